@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/amend-parking-backend/internal/config"
+)
+
+const configUsage = "usage: config check"
+
+// runConfig implements the config subcommand:
+//
+//	config check  print the effective configuration with secrets masked and
+//	              every invalid setting
+//
+// It returns the process exit code.
+func runConfig(configFile string, args []string) int {
+	if len(args) != 1 || args[0] != "check" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(configFile)
+	var validationErr *config.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, setting := range cfg.Effective() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	w.Flush()
+
+	if validationErr != nil {
+		fmt.Fprintf(os.Stderr, "\n%d invalid setting(s):\n", len(validationErr.Errors))
+		for _, fieldErr := range validationErr.Errors {
+			fmt.Fprintf(os.Stderr, "  %s\n", fieldErr)
+		}
+		return 1
+	}
+	fmt.Println("\nConfiguration is valid")
+	return 0
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+)
+
+// runHealthcheck implements the healthcheck subcommand used by container
+// healthchecks: it probes /readyz of the local server over HTTP, or over
+// HTTPS when TLS is enabled. The server certificate is not verified, since
+// the probe only asks the process on this host whether it is ready; under
+// TLS_CLIENT_AUTH=require it presents TLS_HEALTHCHECK_CERT_FILE.
+func runHealthcheck(cfg *config.Config) error {
+	scheme := "http"
+	transport := &http.Transport{}
+	if cfg.TLS.Enabled() {
+		scheme = "https"
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		if cfg.TLS.HealthcheckCertFile != "" {
+			cert, err := tls.LoadX509KeyPair(cfg.TLS.HealthcheckCertFile, cfg.TLS.HealthcheckKeyFile)
+			if err != nil {
+				return fmt.Errorf("load healthcheck certificate: %w", err)
+			}
+			transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("%s://localhost:%s/readyz", scheme, cfg.ServerPort))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("readyz responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -2,19 +2,20 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/amend-parking-backend/internal/api"
+	"github.com/amend-parking-backend/internal/app"
 	"github.com/amend-parking-backend/internal/config"
-	"github.com/amend-parking-backend/internal/database"
-	"github.com/amend-parking-backend/internal/repository"
-	"github.com/amend-parking-backend/internal/service"
-	"github.com/gin-contrib/cors"
+	"github.com/amend-parking-backend/internal/tlsutil"
+	"github.com/amend-parking-backend/internal/tracing"
 	"github.com/gin-gonic/gin"
 
 	_ "github.com/amend-parking-backend/docs"
@@ -41,40 +42,109 @@ import (
 // @BasePath  /
 
 func main() {
-	config.LoadConfig()
+	configFile := flag.String("config", "", "YAML or TOML config file, layered under environment variables (default $CONFIG_FILE)")
+	flag.Parse()
+	args := flag.Args()
 
-	if err := database.InitializeDatabase(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfig(*configFile, args[1:]))
 	}
-	defer database.CloseDatabase()
 
-	log.Println("Application startup")
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(args) > 0 && args[0] == "healthcheck" {
+		if err := runHealthcheck(cfg); err != nil {
+			log.Fatalf("Healthcheck failed: %v", err)
+		}
+		return
+	}
 
-	router := gin.Default()
+	if len(args) > 0 && args[0] == "migrate" {
+		if err := runMigrate(cfg, args[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
 
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "accept", "origin", "Cache-Control", "X-Requested-With", "X-API-Key"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: false,
-		MaxAge:           12 * time.Hour,
-	}))
+	if !strings.EqualFold(cfg.LoggingLevel, "DEBUG") {
+		gin.SetMode(gin.ReleaseMode)
+	}
 
-	repo := repository.NewRepository()
-	svc := service.NewService(repo)
+	application, err := app.New(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
+	logger := application.Logger()
+	slog.SetDefault(logger)
 
-	api.SetupRoutes(router, svc)
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		fatal(logger, "Failed to initialize tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	logger.Info("Application startup")
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// The server starts right away and answers 503 until MongoDB is reachable,
+	// so that the service survives the database starting after it.
+	go func() {
+		if err := application.Run(backgroundCtx); err != nil {
+			fatal(logger, "Application failed", err)
+		}
+	}()
 
 	srv := &http.Server{
-		Addr:    ":" + config.Settings.ServerPort,
-		Handler: router,
+		Addr:     ":" + cfg.ServerPort,
+		Handler:  application,
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	if cfg.TLS.Enabled() {
+		certs, err := tlsutil.NewCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, logger)
+		if err != nil {
+			fatal(logger, "Failed to load TLS certificate", err)
+		}
+		srv.TLSConfig, err = tlsutil.ServerConfig(cfg.TLS, certs)
+		if err != nil {
+			fatal(logger, "Failed to configure TLS", err)
+		}
+		go certs.Run(backgroundCtx, cfg.TLS.ReloadPeriod)
 	}
 
 	go func() {
-		log.Printf("Server starting on port %s", config.Settings.ServerPort)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+		logger.Info("Server starting", "port", cfg.ServerPort, "tls", cfg.TLS.Enabled())
+		var err error
+		if cfg.TLS.Enabled() {
+			// The certificate comes from TLSConfig.GetCertificate.
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal(logger, "Failed to start server", err)
+		}
+	}()
+
+	// SIGHUP reloads the runtime settings without dropping requests.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if _, err := application.Reload(backgroundCtx); err != nil {
+				logger.Error("Failed to reload configuration", "error", err)
+			}
 		}
 	}()
 
@@ -82,14 +152,27 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Application shutdown")
+	logger.Info("Application shutdown")
+	application.SetShuttingDown()
+	stopBackground()
+
+	// Keep serving while load balancers notice the failing readiness probe.
+	time.Sleep(cfg.ShutdownDrainDelay)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal(logger, "Server forced to shutdown", err)
 	}
+	if err := application.Close(ctx); err != nil {
+		logger.Error("Failed to close database connection", "error", err)
+	}
+
+	logger.Info("Server exited")
+}
 
-	log.Println("Server exited")
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
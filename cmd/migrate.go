@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/database"
+	"github.com/amend-parking-backend/internal/logging"
+	"github.com/amend-parking-backend/internal/migrations"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate implements the migrate subcommand:
+//
+//	migrate up            apply all pending migrations
+//	migrate down [steps]  roll back the last steps migrations (default 1)
+//	migrate status        list migrations and when they were applied
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	steps := 1
+	switch {
+	case args[0] == "down" && len(args) > 1:
+		var err error
+		steps, err = strconv.Atoi(args[1])
+		if err != nil || steps < 1 {
+			return fmt.Errorf("invalid number of steps %q", args[1])
+		}
+	case args[0] != "up" && args[0] != "down" && args[0] != "status":
+		return errors.New(migrateUsage)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger, _ := logging.New(cfg)
+	db, err := database.New(cfg, logger)
+	if err != nil {
+		return err
+	}
+	if err := db.Connect(ctx); err != nil {
+		return err
+	}
+	defer db.Close(context.Background())
+
+	migrator := migrations.NewMigrator(db.DB, logger)
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		fmt.Printf("Applied %d migration(s)\n", applied)
+		return err
+	case "down":
+		rolledBack, err := migrator.Down(ctx, steps)
+		fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tAPPLIED AT\tDESCRIPTION")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, appliedAt, status.Description)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -24,14 +24,55 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/parking/free-spaces-count": {
+        "/admin/access-policy": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Возвращает количество свободных парковочных мест",
+                "description": "Возвращает режим доступа на парковку: open — допускаются все автомобили, кроме запрещённых, allow_list — только зарегистрированные автомобили и номера из списка разрешённых",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Получить режим доступа",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccessPolicy"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Переключает режим доступа на парковку. Изменение записывается в журнал аудита",
                 "consumes": [
                     "application/json"
                 ],
@@ -39,16 +80,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "parking"
+                    "admin"
+                ],
+                "summary": "Изменить режим доступа",
+                "parameters": [
+                    {
+                        "description": "Режим доступа",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetAccessModeSchema"
+                        }
+                    }
                 ],
-                "summary": "Получить количество свободных мест",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccessPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "type": "integer"
+                                "type": "string"
                             }
                         }
                     },
@@ -73,38 +131,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking/free-up": {
-            "post": {
+        "/admin/audit-log": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Освобождает указанное парковочное место",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает последние записи журнала аудита: изменения режима доступа и списков номеров, а также отказы в парковке, начиная с последних. Повторные отказы одному номеру в течение часа объединены в одну запись с их числом в count; записи об отказах хранятся AUDIT_DENIAL_RETENTION",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking"
+                    "admin"
                 ],
-                "summary": "Освободить парковочное место",
+                "summary": "Журнал аудита",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Номер парковочного места",
-                        "name": "place_number",
-                        "in": "query",
-                        "required": true
+                        "description": "Количество записей (по умолчанию 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
                         }
                     },
                     "400": {
@@ -137,32 +194,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking/occupied-spaces-list": {
+        "/admin/capacity": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Возвращает список всех занятых парковочных мест",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает количество мест, число занятых и свободных мест и активные сессии на местах за пределами текущего количества мест",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking"
+                    "admin"
                 ],
-                "summary": "Получить список занятых мест",
+                "summary": "Получить вместимость парковки",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.ParkingSpaceLog"
-                            }
+                            "$ref": "#/definitions/service.Capacity"
                         }
                     },
                     "401": {
@@ -184,16 +235,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/parking/park-car": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Занимает свободное парковочное место для автомобиля",
+                "description": "Изменяет количество мест без перезапуска. Количество можно уменьшить, даже если заняты места за новым пределом: такие сессии помечаются полем out_of_range_at, а их места не выдаются, пока автомобили не уедут или не будут перемещены. Изменение действует до перезапуска или до перезагрузки конфигурации с изменённым PARKING_SLOTS_COUNT",
                 "consumes": [
                     "application/json"
                 ],
@@ -201,17 +250,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "parking"
+                    "admin"
                 ],
-                "summary": "Припарковать автомобиль",
+                "summary": "Изменить количество мест",
                 "parameters": [
                     {
-                        "description": "Данные автомобиля",
+                        "description": "Новое количество мест",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.AddParkingSpaceLogSchema"
+                            "$ref": "#/definitions/api.SetCapacitySchema"
                         }
                     }
                 ],
@@ -219,7 +268,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                            "$ref": "#/definitions/service.Capacity"
                         }
                     },
                     "400": {
@@ -252,37 +301,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/parking/parking-space-logs": {
+        "/admin/plate-lists/{list}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Возвращает логи парковочных мест по имени и фамилии владельца",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает список запрещённых (deny) или разрешённых (allow) номеров, упорядоченный по номеру",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "parking"
+                    "admin"
                 ],
-                "summary": "Получить логи парковочных мест",
+                "summary": "Список номеров",
                 "parameters": [
                     {
+                        "enum": [
+                            "deny",
+                            "allow"
+                        ],
                         "type": "string",
-                        "description": "Имя владельца",
-                        "name": "first_name",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Фамилия владельца",
-                        "name": "last_name",
-                        "in": "query",
+                        "description": "Список",
+                        "name": "list",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -292,9 +335,84 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.ParkingSpaceLog"
+                                "$ref": "#/definitions/models.PlateListEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет номер в список запрещённых (deny) или разрешённых (allow). Номер нормализуется, поэтому совпадает в любом написании. Изменение записывается в журнал аудита",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Добавить номер в список",
+                "parameters": [
+                    {
+                        "enum": [
+                            "deny",
+                            "allow"
+                        ],
+                        "type": "string",
+                        "description": "Список",
+                        "name": "list",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Номер и причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AddPlateListEntrySchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.PlateListEntry"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -314,6 +432,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -325,47 +461,3810 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.AddParkingSpaceLogSchema": {
-            "type": "object",
-            "required": [
-                "car_make",
-                "first_name",
-                "last_name",
-                "license_plate"
-            ],
-            "properties": {
-                "car_make": {
-                    "type": "string",
-                    "example": "Toyota"
-                },
-                "first_name": {
-                    "type": "string",
-                    "example": "Иван"
-                },
-                "last_name": {
-                    "type": "string",
-                    "example": "Иванов"
-                },
-                "license_plate": {
-                    "type": "string",
-                    "example": "А123БВ777"
+        },
+        "/admin/plate-lists/{list}/{license_plate}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет номер в любом написании из списка запрещённых (deny) или разрешённых (allow). Изменение записывается в журнал аудита",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить номер из списка",
+                "parameters": [
+                    {
+                        "enum": [
+                            "deny",
+                            "allow"
+                        ],
+                        "type": "string",
+                        "description": "Список",
+                        "name": "list",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Номер автомобиля",
+                        "name": "license_plate",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Номер удалён из списка"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "models.ParkingSpaceLog": {
-            "type": "object",
-            "properties": {
-                "car_make": {
-                    "type": "string",
+        "/admin/reload": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Перечитывает конфигурацию и применяет настройки, не требующие перезапуска: количество мест, тариф, стратегию распределения мест, ограничения времени стоянки, лимит активных сессий на клиента и уровень логов. Уменьшение количества мест, при котором занятые места оказались бы за его пределами, отклоняется; сессии, уже помеченные out_of_range_at, не учитываются. Настройки, заданные переменными окружения, перезагрузкой не меняются: если файл конфигурации задаёт для них другое значение, они перечисляются в shadowed_by_env",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Перечитать конфигурацию",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReloadConfigResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ConfigErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/drivers": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает зарегистрированных водителей по алфавиту",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Список водителей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Driver"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет водителя в реестр. Адрес водителя передаётся в заголовке Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Зарегистрировать водителя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные водителя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DriverSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Driver"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/drivers/{driver_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Получить водителя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор водителя",
+                        "name": "driver_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Driver"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Заменяет данные водителя. Начатые ранее сессии сохраняют прежние данные",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Изменить водителя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор водителя",
+                        "name": "driver_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные водителя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DriverSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Driver"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет водителя из реестра. Водителя с зарегистрированными автомобилями удалить нельзя",
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Удалить водителя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор водителя",
+                        "name": "driver_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Водитель удалён"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/drivers/{driver_id}/vehicles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Автомобили водителя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор водителя",
+                        "name": "driver_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Vehicle"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/lots": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает парковки с количеством мест и их занятостью. Экземпляр сервиса обслуживает одну парковку",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lots"
+                ],
+                "summary": "Список парковок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Lot"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/lots/{lot_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает количество мест парковки и их занятость",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lots"
+                ],
+                "summary": "Получить парковку",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор парковки",
+                        "name": "lot_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Lot"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/lots/{lot_id}/availability": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает общее число мест, число занятых, свободных, зарезервированных и выведенных из эксплуатации мест, номера свободных мест и разбивку по зонам, уровням и типам мест",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lots"
+                ],
+                "summary": "Получить доступность мест парковки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор парковки",
+                        "name": "lot_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Availability"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/lots/{lot_id}/places": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает все места парковки со статусом (free, occupied, reserved, out_of_service), зоной, уровнем, типом, временем смены статуса и краткими данными занимающей место сессии. Версия карты передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lots"
+                ],
+                "summary": "Получить карту мест парковки",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор парковки",
+                        "name": "lot_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный ранее",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Place"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Карта не изменилась"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance-blocks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает блокировки мест, начиная с последних. С active=true — только запланированные и действующие",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Список блокировок мест",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только запланированные и действующие блокировки",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceBlock"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создаёт блокировку места на время обслуживания (например, уборки снега или ремонта). С момента from (по умолчанию — сразу) и до until (по умолчанию — до завершения блокировки) место не выдаётся новым автомобилям и не считается свободным. Автомобиль, уже стоящий на месте, остаётся на нём. По истечении until блокировка снимается автоматически. Адрес блокировки передаётся в заголовке Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Вывести место из эксплуатации",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Место, причина и период блокировки",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateMaintenanceBlockSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceBlock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/maintenance-blocks/{block_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает блокировку места и её состояние: scheduled, active, expired или ended",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Получить блокировку места",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор блокировки",
+                        "name": "block_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceBlock"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает место в эксплуатацию. Запланированная блокировка отменяется",
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Завершить блокировку места",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор блокировки",
+                        "name": "block_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Блокировка завершена"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/permits": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает пропуска, начиная с последних. С active=true — только запланированные и действующие",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permits"
+                ],
+                "summary": "Список пропусков",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Только запланированные и действующие пропуска",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Permit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Выдаёт пропуск зарегистрированному автомобилю (vehicle_id) или водителю (driver_id) на период с valid_from (по умолчанию — сразу) до valid_until. Владелец пропуска паркуется без оплаты, получает выделенное место place_number, пока оно свободно, и допускается в зоны пропусков из zones (по умолчанию — во все зоны PERMIT_ZONES). Адрес пропуска передаётся в заголовке Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permits"
+                ],
+                "summary": "Выдать пропуск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Владелец, период, зоны и выделенное место",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreatePermitSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Permit"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/permits/expiring": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает неотозванные пропуска, срок которых истекает в течение within (по умолчанию — PERMIT_EXPIRY_NOTICE), начиная с ближайших",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permits"
+                ],
+                "summary": "Истекающие пропуска",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Период в нотации Go, например 72h",
+                        "name": "within",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Permit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/permits/{permit_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает пропуск и его состояние: scheduled, active, expired или revoked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permits"
+                ],
+                "summary": "Получить пропуск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пропуска",
+                        "name": "permit_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Permit"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Досрочно прекращает действие пропуска и освобождает его выделенное место. Автомобиль, уже припаркованный по пропуску, остаётся на месте, и текущая сессия не оплачивается",
+                "tags": [
+                    "permits"
+                ],
+                "summary": "Отозвать пропуск",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор пропуска",
+                        "name": "permit_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Пропуск отозван"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает активные парковочные сессии. С параметрами first_name и last_name — только сессии владельца, с overstayed=true — только превысившие максимальное время стоянки",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Список активных сессий",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя владельца",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фамилия владельца",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только превышения времени стоянки",
+                        "name": "overstayed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ParkingSpaceLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Занимает свободное место согласно стратегии распределения. Автомобиль задаётся идентификатором vehicle_id или номером license_plate зарегистрированного автомобиля либо всеми данными автомобиля и водителя; переданные имя, фамилия и марка заменяют данные реестра. Адрес новой сессии передаётся в заголовке Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Начать парковочную сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные автомобиля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AddParkingSpaceLogSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/{log_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает парковочную сессию по её идентификатору. Версия сессии передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Получить парковочную сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный ранее",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "304": {
+                        "description": "Сессия не изменилась"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает активную сессию и освобождает занятое ею место. Завершённая сессия остаётся доступной для чтения. С заголовком If-Match сессия завершается, только если её ETag не изменился",
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Завершить парковочную сессию",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сессии или список ETag через запятую",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Сессия завершена"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Атомарно освобождает текущее место сессии и занимает указанное в place_number, сохраняя ту же сессию. Если номер места не указан, свободное место выбирается согласно стратегии распределения. С заголовком If-Match сессия изменяется, только если её ETag не изменился",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Переместить автомобиль на другое место",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сессии или список ETag через запятую",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое место",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MoveParkingSessionSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/sessions/{log_id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает стоимость и длительность сессии. Для активной сессии чек предварительный и рассчитан на текущий момент",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Получить чек парковочной сессии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingReceipt"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/spaces": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает все места парковки по порядку номеров вместе с занимающими их сессиями",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spaces"
+                ],
+                "summary": "Список парковочных мест",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/service.Space"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/spaces/{place_number}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает место и занимающую его сессию",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spaces"
+                ],
+                "summary": "Получить парковочное место",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер парковочного места",
+                        "name": "place_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Space"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/spaces/{place_number}/session": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает сессию, занимающую место",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spaces"
+                ],
+                "summary": "Освободить парковочное место",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер парковочного места",
+                        "name": "place_number",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Место освобождено"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vehicles": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает зарегистрированные автомобили; с license_plate — только автомобиль с этим номером в любом написании",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Список автомобилей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Номер автомобиля",
+                        "name": "license_plate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Vehicle"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Добавляет автомобиль водителя в реестр. Номер хранится в нормализованном виде: заглавными буквами, без пробелов и дефисов, с латинскими буквами, похожими на кириллические, заменёнными на кириллические. Адрес автомобиля передаётся в заголовке Location",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Зарегистрировать автомобиль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные автомобиля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.VehicleSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Vehicle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/vehicles/{vehicle_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Получить автомобиль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор автомобиля",
+                        "name": "vehicle_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Vehicle"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Заменяет данные автомобиля, в том числе его владельца. Начатые ранее сессии сохраняют прежние данные",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Изменить автомобиль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор автомобиля",
+                        "name": "vehicle_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные автомобиля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.VehicleSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Vehicle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаляет автомобиль из реестра. Его сессии сохраняют данные автомобиля",
+                "tags": [
+                    "registry"
+                ],
+                "summary": "Удалить автомобиль",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор автомобиля",
+                        "name": "vehicle_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Автомобиль удалён"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Сообщает, что процесс запущен и обрабатывает запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка жизнеспособности",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/free-spaces-count": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает количество свободных парковочных мест",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Получить количество свободных мест",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/free-up": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Освобождает указанное парковочное место",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Освободить парковочное место",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер парковочного места",
+                        "name": "place_number",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/occupied-spaces-list": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает список всех занятых парковочных мест",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Получить список занятых мест",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ParkingSpaceLog"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/overstays": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает активные сессии, превысившие максимальное время стоянки для своего места",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Получить список превышений времени стоянки",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ParkingSpaceLog"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/park-car": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Занимает свободное парковочное место для автомобиля. Автомобиль задаётся идентификатором vehicle_id или номером license_plate зарегистрированного автомобиля либо всеми данными автомобиля и водителя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Припарковать автомобиль",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Данные автомобиля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.AddParkingSpaceLogSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/parking-space-logs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает логи парковочных мест по имени и фамилии владельца",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "parking"
+                ],
+                "summary": "Получить логи парковочных мест",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя владельца",
+                        "name": "first_name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фамилия владельца",
+                        "name": "last_name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ParkingSpaceLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/sessions/{log_id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает парковочную сессию по её идентификатору. Версия сессии передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Получить парковочную сессию",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag, полученный ранее",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "304": {
+                        "description": "Сессия не изменилась"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/sessions/{log_id}/end": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Завершает активную парковочную сессию и освобождает занятое ею место. С заголовком If-Match сессия завершается, только если её ETag не изменился",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Завершить парковочную сессию",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сессии или список ETag через запятую",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/sessions/{log_id}/move": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Атомарно освобождает текущее место сессии и занимает новое, сохраняя ту же сессию. Если номер места не указан, свободное место выбирается согласно стратегии распределения",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Переместить автомобиль на другое место",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag сессии или список ETag через запятую",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое место",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.MoveParkingSessionSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingSpaceLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/parking/sessions/{log_id}/receipt": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Возвращает стоимость и длительность сессии. Для активной сессии чек предварительный и рассчитан на текущий момент",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Получить чек парковочной сессии",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Идентификатор сессии",
+                        "name": "log_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ParkingReceipt"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет доступность MongoDB, создание индексов и корректность конфигурации. Во время остановки сервиса возвращает 503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AddParkingSpaceLogSchema": {
+            "type": "object",
+            "properties": {
+                "car_make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "Иван"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Иванов"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123БВ777"
+                },
+                "vehicle_id": {
+                    "type": "string",
+                    "example": "c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"
+                }
+            }
+        },
+        "api.AddPlateListEntrySchema": {
+            "type": "object",
+            "required": [
+                "license_plate"
+            ],
+            "properties": {
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123ВС777"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Неоплаченные штрафы"
+                }
+            }
+        },
+        "api.ConfigErrorResponse": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string",
+                    "example": "invalid configuration"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "PARKING_SLOTS_COUNT must be positive",
+                        " got 0"
+                    ]
+                }
+            }
+        },
+        "api.CreateMaintenanceBlockSchema": {
+            "type": "object",
+            "required": [
+                "place_number",
+                "reason"
+            ],
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "place_number": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 7
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Уборка снега"
+                },
+                "until": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "api.CreatePermitSchema": {
+            "type": "object",
+            "required": [
+                "valid_until"
+            ],
+            "properties": {
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "place_number": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 7
+                },
+                "valid_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "valid_until": {
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                },
+                "vehicle_id": {
+                    "type": "string",
+                    "example": "c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "A"
+                    ]
+                }
+            }
+        },
+        "api.DriverSchema": {
+            "type": "object",
+            "required": [
+                "first_name",
+                "last_name"
+            ],
+            "properties": {
+                "first_name": {
+                    "type": "string",
+                    "example": "Иван"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Иванов"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+79991234567"
+                }
+            }
+        },
+        "api.MoveParkingSessionSchema": {
+            "type": "object",
+            "properties": {
+                "place_number": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 7
+                }
+            }
+        },
+        "api.ReloadConfigResponse": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "PARKING_SLOTS_COUNT"
+                    ]
+                },
+                "restart_required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "MONGODB_URL"
+                    ]
+                },
+                "shadowed_by_env": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "PARKING_HOURLY_RATE"
+                    ]
+                }
+            }
+        },
+        "api.SetAccessModeSchema": {
+            "type": "object",
+            "required": [
+                "mode"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "allow_list"
+                    ],
+                    "example": "allow_list"
+                }
+            }
+        },
+        "api.SetCapacitySchema": {
+            "type": "object",
+            "required": [
+                "slots_count"
+            ],
+            "properties": {
+                "slots_count": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 40
+                }
+            }
+        },
+        "api.VehicleSchema": {
+            "type": "object",
+            "required": [
+                "driver_id",
+                "license_plate",
+                "make"
+            ],
+            "properties": {
+                "colour": {
+                    "type": "string",
+                    "example": "белый"
+                },
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123БВ777"
+                },
+                "make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "Camry"
+                },
+                "size_class": {
+                    "type": "string",
+                    "enum": [
+                        "small",
+                        "medium",
+                        "large"
+                    ],
+                    "example": "medium"
+                }
+            }
+        },
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": ""
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.AccessPolicy": {
+            "type": "object",
+            "properties": {
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "allow_list"
+                    ],
+                    "example": "allow_list"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T09:00:00Z"
+                },
+                "updated_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "access_mode.changed",
+                        "plate.listed",
+                        "plate.unlisted",
+                        "park.denied"
+                    ],
+                    "example": "plate.listed"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "at": {
+                    "type": "string",
+                    "example": "2024-01-01T09:00:00Z"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "entry_id": {
+                    "type": "string",
+                    "example": "6c1e8a4d-3b7f-4d2e-9a5c-0f8b2d4e6a1c"
+                },
+                "last_at": {
+                    "type": "string",
+                    "example": "2024-01-01T09:20:00Z"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123ВС777"
+                },
+                "list": {
+                    "type": "string",
+                    "example": "deny"
+                },
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "allow_list"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Неоплаченные штрафы"
+                }
+            }
+        },
+        "models.Driver": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "Иван"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Иванов"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+79991234567"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "models.MaintenanceBlock": {
+            "type": "object",
+            "properties": {
+                "block_id": {
+                    "type": "string",
+                    "example": "5d0c1a0e-3f7e-4c2b-9a55-6a1f0e2b7c44"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T07:55:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "ended_at": {
+                    "type": "string",
+                    "example": "2024-01-01T10:30:00Z"
+                },
+                "ended_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-01-01T08:00:00Z"
+                },
+                "place_number": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Уборка снега"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "scheduled",
+                        "active",
+                        "expired",
+                        "ended"
+                    ],
+                    "example": "active"
+                },
+                "until": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "models.ParkingReceipt": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "billed_hours": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "car_make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "ended_at": {
+                    "type": "string",
+                    "example": "2024-01-01T14:00:00Z"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "Иван"
+                },
+                "hourly_rate": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "is_final": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Иванов"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123БВ777"
+                },
+                "log_id": {
+                    "type": "string",
+                    "example": "0b6b1b9e-2a55-4d8e-9c36-1f9a5f1f5d3e"
+                },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaceMove"
+                    }
+                },
+                "permit_id": {
+                    "type": "string",
+                    "example": "9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"
+                },
+                "permit_minutes": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "place_number": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "models.ParkingSpaceLog": {
+            "type": "object",
+            "properties": {
+                "car_make": {
+                    "type": "string",
                     "example": "Toyota"
                 },
                 "created_at": {
                     "type": "string",
                     "example": "2024-01-01T12:00:00Z"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
                 "first_name": {
                     "type": "string",
                     "example": "Иван"
@@ -394,9 +4293,434 @@ const docTemplate = `{
                     "type": "string",
                     "example": "log-123"
                 },
+                "moves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaceMove"
+                    }
+                },
+                "out_of_range_at": {
+                    "type": "string",
+                    "example": "2024-01-02T09:00:00Z"
+                },
+                "overstayed_at": {
+                    "type": "string",
+                    "example": "2024-01-04T12:00:00Z"
+                },
+                "permit_id": {
+                    "type": "string",
+                    "example": "9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"
+                },
+                "place_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "vehicle_id": {
+                    "type": "string",
+                    "example": "c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Permit": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-28T09:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "permit_id": {
+                    "type": "string",
+                    "example": "9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"
+                },
                 "place_number": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "reminder_sent_at": {
+                    "type": "string",
+                    "example": "2024-01-25T00:00:00Z"
+                },
+                "revoked_at": {
+                    "type": "string",
+                    "example": "2024-01-15T18:00:00Z"
+                },
+                "revoked_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "scheduled",
+                        "active",
+                        "expired",
+                        "revoked"
+                    ],
+                    "example": "active"
+                },
+                "valid_from": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "valid_until": {
+                    "type": "string",
+                    "example": "2024-02-01T00:00:00Z"
+                },
+                "vehicle_id": {
+                    "type": "string",
+                    "example": "c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"
+                },
+                "zones": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "A"
+                    ]
+                }
+            }
+        },
+        "models.PlaceMove": {
+            "type": "object",
+            "properties": {
+                "from_place": {
                     "type": "integer",
                     "example": 1
+                },
+                "moved_at": {
+                    "type": "string",
+                    "example": "2024-01-01T13:00:00Z"
+                },
+                "to_place": {
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "models.PlateListEntry": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T09:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "key:3f2a9c1b7d4e"
+                },
+                "entry_id": {
+                    "type": "string",
+                    "example": "2f7d9b3e-1c4a-4e6b-8d0f-5a3c7e9b1d2f"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123ВС777"
+                },
+                "list": {
+                    "type": "string",
+                    "enum": [
+                        "deny",
+                        "allow"
+                    ],
+                    "example": "deny"
+                },
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Неоплаченные штрафы"
+                }
+            }
+        },
+        "models.Vehicle": {
+            "type": "object",
+            "properties": {
+                "colour": {
+                    "type": "string",
+                    "example": "белый"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "driver_id": {
+                    "type": "string",
+                    "example": "8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"
+                },
+                "license_plate": {
+                    "type": "string",
+                    "example": "А123БВ777"
+                },
+                "make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "model": {
+                    "type": "string",
+                    "example": "Camry"
+                },
+                "size_class": {
+                    "type": "string",
+                    "enum": [
+                        "small",
+                        "medium",
+                        "large"
+                    ],
+                    "example": "medium"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "vehicle_id": {
+                    "type": "string",
+                    "example": "c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"
+                }
+            }
+        },
+        "service.Availability": {
+            "type": "object",
+            "properties": {
+                "by_level": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.AvailabilityCounts"
+                    }
+                },
+                "by_type": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.AvailabilityCounts"
+                    }
+                },
+                "by_zone": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.AvailabilityCounts"
+                    }
+                },
+                "free": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "free_places": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        5
+                    ]
+                },
+                "occupied": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "out_of_range": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "out_of_service": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "permit_only": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "reserved": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 52
+                }
+            }
+        },
+        "service.AvailabilityCounts": {
+            "type": "object",
+            "properties": {
+                "free": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "occupied": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "out_of_service": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "permit_only": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "reserved": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 52
+                }
+            }
+        },
+        "service.Capacity": {
+            "type": "object",
+            "properties": {
+                "free": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "occupied": {
+                    "type": "integer",
+                    "example": 38
+                },
+                "out_of_range": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ParkingSpaceLog"
+                    }
+                },
+                "slots_count": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "service.Lot": {
+            "type": "object",
+            "properties": {
+                "free": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "lot_id": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "occupied": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "slots_count": {
+                    "type": "integer",
+                    "example": 52
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Parking Service"
+                }
+            }
+        },
+        "service.Place": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "$ref": "#/definitions/models.MaintenanceBlock"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "permit_id": {
+                    "description": "PermitID is the permit that reserves the place, if any.",
+                    "type": "string",
+                    "example": "9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"
+                },
+                "place_number": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "session": {
+                    "$ref": "#/definitions/service.PlaceSession"
+                },
+                "since": {
+                    "description": "Since is when the place got its status. It is missing for places that\nhave never been used.",
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "free",
+                        "permit_only",
+                        "occupied",
+                        "reserved",
+                        "out_of_service"
+                    ],
+                    "example": "occupied"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "standard"
+                },
+                "zone": {
+                    "type": "string",
+                    "example": "A"
+                }
+            }
+        },
+        "service.PlaceSession": {
+            "type": "object",
+            "properties": {
+                "car_make": {
+                    "type": "string",
+                    "example": "Toyota"
+                },
+                "log_id": {
+                    "type": "string",
+                    "example": "0b6b1b9e-2a55-4d8e-9c36-1f9a5f1f5d3e"
+                },
+                "overstayed_at": {
+                    "type": "string",
+                    "example": "2024-01-04T12:00:00Z"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "service.Space": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "$ref": "#/definitions/models.MaintenanceBlock"
+                },
+                "occupied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "place_number": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "session": {
+                    "$ref": "#/definitions/models.ParkingSpaceLog"
                 }
             }
         }
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Получить режим доступа
+// @Description  Возвращает режим доступа на парковку: open — допускаются все автомобили, кроме запрещённых, allow_list — только зарегистрированные автомобили и номера из списка разрешённых
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  models.AccessPolicy
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/access-policy [get]
+func (h *AdminHandlers) GetAccessPolicy(c *gin.Context) {
+	policy, err := h.service.GetAccessPolicy(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// @Summary      Изменить режим доступа
+// @Description  Переключает режим доступа на парковку. Изменение записывается в журнал аудита
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      SetAccessModeSchema  true  "Режим доступа"
+// @Success      200      {object}  models.AccessPolicy
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/access-policy [put]
+func (h *AdminHandlers) SetAccessPolicy(c *gin.Context) {
+	var body SetAccessModeSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	policy, err := h.service.SetAccessMode(c.Request.Context(), ClientID(c), body.Mode)
+	if err != nil {
+		c.JSON(accessErrorStatus(err), gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// @Summary      Список номеров
+// @Description  Возвращает список запрещённых (deny) или разрешённых (allow) номеров, упорядоченный по номеру
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        list  path      string  true  "Список"  Enums(deny,  allow)
+// @Success      200   {array}   models.PlateListEntry
+// @Failure      401   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/plate-lists/{list} [get]
+func (h *AdminHandlers) GetPlateList(c *gin.Context) {
+	entries, err := h.service.GetPlateList(c.Request.Context(), c.Param("list"))
+	if err != nil {
+		c.JSON(accessErrorStatus(err), gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(entries))
+}
+
+// @Summary      Добавить номер в список
+// @Description  Добавляет номер в список запрещённых (deny) или разрешённых (allow). Номер нормализуется, поэтому совпадает в любом написании. Изменение записывается в журнал аудита
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        list     path      string                   true  "Список"  Enums(deny,  allow)
+// @Param        request  body      AddPlateListEntrySchema  true  "Номер и причина"
+// @Success      201      {object}  models.PlateListEntry
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/plate-lists/{list} [post]
+func (h *AdminHandlers) AddPlateListEntry(c *gin.Context) {
+	var body AddPlateListEntrySchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	entry, err := h.service.AddPlateListEntry(c.Request.Context(), ClientID(c), c.Param("list"), body.LicensePlate, body.Reason)
+	if err != nil {
+		c.JSON(accessErrorStatus(err), gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// @Summary      Удалить номер из списка
+// @Description  Удаляет номер в любом написании из списка запрещённых (deny) или разрешённых (allow). Изменение записывается в журнал аудита
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Param        list           path      string  true  "Список"  Enums(deny,  allow)
+// @Param        license_plate  path      string  true  "Номер автомобиля"
+// @Success      204            "Номер удалён из списка"
+// @Failure      401            {object}  map[string]string
+// @Failure      404            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /admin/plate-lists/{list}/{license_plate} [delete]
+func (h *AdminHandlers) RemovePlateListEntry(c *gin.Context) {
+	err := h.service.RemovePlateListEntry(c.Request.Context(), ClientID(c), c.Param("list"), c.Param("license_plate"))
+	if err != nil {
+		c.JSON(accessErrorStatus(err), gin.H{"detail": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Журнал аудита
+// @Description  Возвращает последние записи журнала аудита: изменения режима доступа и списков номеров, а также отказы в парковке, начиная с последних. Повторные отказы одному номеру в течение часа объединены в одну запись с их числом в count; записи об отказах хранятся AUDIT_DENIAL_RETENTION
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        limit  query     int  false  "Количество записей (по умолчанию 100)"
+// @Success      200    {array}   models.AuditEntry
+// @Failure      400    {object}  map[string]string
+// @Failure      401    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /admin/audit-log [get]
+func (h *AdminHandlers) GetAuditLog(c *gin.Context) {
+	limit := service.DefaultAuditLimit
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid limit"})
+			return
+		}
+	}
+
+	entries, err := h.service.GetAuditLog(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(entries))
+}
+
+func accessErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUnknownPlateList),
+		errors.Is(err, service.ErrPlateNotListed):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrPlateAlreadyListed):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidAccessMode):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Reloader re-reads the configuration and applies the runtime settings.
+type Reloader interface {
+	Reload(ctx context.Context) (config.ReloadResult, error)
+}
+
+type AdminHandlers struct {
+	reloader Reloader
+	service  *service.Service
+}
+
+func NewAdminHandlers(reloader Reloader, svc *service.Service) *AdminHandlers {
+	return &AdminHandlers{reloader: reloader, service: svc}
+}
+
+// @Summary      Перечитать конфигурацию
+// @Description  Перечитывает конфигурацию и применяет настройки, не требующие перезапуска: количество мест, тариф, стратегию распределения мест, ограничения времени стоянки, лимит активных сессий на клиента и уровень логов. Уменьшение количества мест, при котором занятые места оказались бы за его пределами, отклоняется; сессии, уже помеченные out_of_range_at, не учитываются. Настройки, заданные переменными окружения, перезагрузкой не меняются: если файл конфигурации задаёт для них другое значение, они перечисляются в shadowed_by_env
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  ReloadConfigResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      422  {object}  ConfigErrorResponse
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/reload [post]
+func (h *AdminHandlers) ReloadConfig(c *gin.Context) {
+	result, err := h.reloader.Reload(c.Request.Context())
+	if err != nil {
+		var validationErr *config.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			problems := make([]string, len(validationErr.Errors))
+			for i, fieldErr := range validationErr.Errors {
+				problems[i] = fieldErr.Error()
+			}
+			c.JSON(http.StatusUnprocessableEntity, ConfigErrorResponse{Detail: "invalid configuration", Errors: problems})
+		case errors.Is(err, service.ErrPlacesStranded):
+			c.JSON(http.StatusConflict, gin.H{"detail": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"detail": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ReloadConfigResponse{
+		Changed:         nonNil(result.Changed),
+		RestartRequired: nonNil(result.RestartRequired),
+		ShadowedByEnv:   nonNil(result.ShadowedByEnv),
+	})
+}
+
+// @Summary      Получить вместимость парковки
+// @Description  Возвращает количество мест, число занятых и свободных мест и активные сессии на местах за пределами текущего количества мест
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {object}  service.Capacity
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/capacity [get]
+func (h *AdminHandlers) GetCapacity(c *gin.Context) {
+	capacity, err := h.service.GetCapacity(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, capacity)
+}
+
+// @Summary      Изменить количество мест
+// @Description  Изменяет количество мест без перезапуска. Количество можно уменьшить, даже если заняты места за новым пределом: такие сессии помечаются полем out_of_range_at, а их места не выдаются, пока автомобили не уедут или не будут перемещены. Изменение действует до перезапуска или до перезагрузки конфигурации с изменённым PARKING_SLOTS_COUNT
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request  body      SetCapacitySchema  true  "Новое количество мест"
+// @Success      200      {object}  service.Capacity
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/capacity [put]
+func (h *AdminHandlers) SetCapacity(c *gin.Context) {
+	var body SetCapacitySchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	capacity, err := h.service.SetCapacity(c.Request.Context(), body.SlotsCount)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidCapacity) {
+			statusCode = http.StatusBadRequest
+		}
+		c.JSON(statusCode, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, capacity)
+}
+
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
@@ -1,16 +1,40 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"slices"
 
 	"github.com/gin-gonic/gin"
-	"github.com/amend-parking-backend/internal/config"
 )
 
 const XAPIKeyHeader = "X-API-Key"
 
-func APIKeyAuth() gin.HandlerFunc {
+const clientIDKey = "client_id"
+
+// ClientID identifies the authenticated caller, e.g. for rate limits, without
+// revealing its credentials.
+func ClientID(c *gin.Context) string {
+	return c.GetString(clientIDKey)
+}
+
+func apiKeyClientID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key:" + hex.EncodeToString(sum[:6])
+}
+
+// Authenticate accepts either a verified TLS client certificate whose common
+// name is mapped in certIdentities or the X-API-Key header. Machine clients
+// such as gate controllers use certificates, everything else the API key.
+func Authenticate(parkingServiceAPIKey string, certIdentities map[string]string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if identity, ok := certificateIdentity(c.Request, certIdentities); ok {
+			c.Set(clientIDKey, "cert:"+identity)
+			c.Next()
+			return
+		}
+
 		apiKey := c.GetHeader(XAPIKeyHeader)
 		if apiKey == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -20,7 +44,7 @@ func APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
-		if apiKey != config.Settings.ParkingServiceAPIKey {
+		if apiKey != parkingServiceAPIKey {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"detail": "Invalid API Key. Check 'X-API-Key' header.",
 			})
@@ -28,6 +52,46 @@ func APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
+		c.Set(clientIDKey, apiKeyClientID(apiKey))
 		c.Next()
 	}
 }
+
+// AuthenticateAdmin guards the admin endpoints. It accepts a client
+// certificate mapped to one of adminIdentities or the X-API-Key header with
+// the admin API key, never the key parking clients hold. With neither
+// configured, the admin endpoints are closed.
+func AuthenticateAdmin(adminAPIKey string, certIdentities map[string]string, adminIdentities []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if identity, ok := certificateIdentity(c.Request, certIdentities); ok && slices.Contains(adminIdentities, identity) {
+			c.Set(clientIDKey, "cert:"+identity)
+			c.Next()
+			return
+		}
+
+		apiKey := c.GetHeader(XAPIKeyHeader)
+		if adminAPIKey == "" || apiKey != adminAPIKey {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"detail": "Invalid admin API Key. Check 'X-API-Key' header.",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(clientIDKey, apiKeyClientID(apiKey))
+		c.Next()
+	}
+}
+
+// certificateIdentity maps the client certificate of a mutual TLS connection
+// to an identity. Only certificates verified against the client CA and whose
+// common name is mapped count.
+func certificateIdentity(r *http.Request, certIdentities map[string]string) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return "", false
+	}
+
+	commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	identity, ok := certIdentities[commonName]
+	return identity, ok
+}
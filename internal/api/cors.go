@@ -0,0 +1,83 @@
+package api
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// exposedHeaders are the response headers of this API that browser clients
+// may read.
+var exposedHeaders = []string{
+	"Content-Length",
+	"ETag",
+	"X-Request-ID",
+	"Idempotent-Replayed",
+	"RateLimit-Limit",
+	"RateLimit-Remaining",
+	"RateLimit-Reset",
+	"Retry-After",
+	"Location",
+	"Deprecation",
+	"Sunset",
+	"Link",
+}
+
+// CORS applies the cross-origin policy from the configuration. Requests with
+// an Origin that is not allowed are rejected with 403. Without configured
+// origins no CORS headers are sent at all, so browsers block cross-origin
+// calls.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	if len(cfg.AllowOrigins) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    exposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+	if slices.Contains(cfg.AllowOrigins, "*") {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOriginFunc = originMatcher(cfg.AllowOrigins)
+	}
+	return cors.New(corsConfig)
+}
+
+// originMatcher matches origins exactly or against patterns in which *
+// stands for one or more host labels or a port, e.g. https://*.example.com
+// or http://localhost:*.
+func originMatcher(origins []string) func(string) bool {
+	exact := make(map[string]bool)
+	var patterns []*regexp.Regexp
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+		if !strings.Contains(origin, "*") {
+			exact[origin] = true
+			continue
+		}
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(origin), `:\*`, `:[0-9]+`)
+		pattern = strings.ReplaceAll(pattern, `\*`, `[a-z0-9-]+(?:\.[a-z0-9-]+)*`)
+		patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+	}
+
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,40 @@
+package api
+
+import "testing"
+
+func TestOriginMatcher(t *testing.T) {
+	allowed := originMatcher([]string{
+		"https://app.example.com",
+		"https://*.example.org",
+		"http://localhost:*",
+	})
+
+	tests := []struct {
+		name   string
+		origin string
+		want   bool
+	}{
+		{name: "exact", origin: "https://app.example.com", want: true},
+		{name: "exact, other case", origin: "HTTPS://App.Example.com", want: true},
+		{name: "exact, other scheme", origin: "http://app.example.com"},
+		{name: "exact, other port", origin: "https://app.example.com:8443"},
+		{name: "exact, other host", origin: "https://api.example.com"},
+		{name: "subdomain", origin: "https://app.example.org", want: true},
+		{name: "nested subdomain", origin: "https://a.b.example.org", want: true},
+		{name: "bare domain", origin: "https://example.org"},
+		{name: "suffix of another domain", origin: "https://app.notexample.org"},
+		{name: "domain as a prefix", origin: "https://app.example.org.evil.com"},
+		{name: "any port", origin: "http://localhost:3000", want: true},
+		{name: "no port", origin: "http://localhost"},
+		{name: "host in the port", origin: "http://localhost:3000.evil.com"},
+		{name: "empty", origin: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowed(tt.origin); got != tt.want {
+				t.Errorf("originMatcher()(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
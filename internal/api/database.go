@@ -0,0 +1,28 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/amend-parking-backend/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireDatabase answers 503 with Retry-After while MongoDB is unreachable
+// instead of letting requests wait for the driver's server selection timeout.
+func RequireDatabase(db *database.Database) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !db.IsAvailable() {
+			retryAfter := math.Ceil(db.RetryAfter().Seconds())
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter)))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"detail": "Database is temporarily unavailable, retry later.",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ETagHeader        = "ETag"
+	IfMatchHeader     = "If-Match"
+	IfNoneMatchHeader = "If-None-Match"
+)
+
+// sessionETag identifies the representation of a session by its version and
+// the overstay and out of range marks, which background checks set without
+// changing the version.
+func sessionETag(log *models.ParkingSpaceLog) string {
+	tag := fmt.Sprintf("%s.%d", log.LogID, log.Version)
+	if log.OverstayedAt != nil {
+		tag += fmt.Sprintf(".o%d", log.OverstayedAt.Unix())
+	}
+	if log.OutOfRangeAt != nil {
+		tag += fmt.Sprintf(".r%d", log.OutOfRangeAt.Unix())
+	}
+	return strconv.Quote(tag)
+}
+
+func setSessionETag(c *gin.Context, log *models.ParkingSpaceLog) {
+	c.Header(ETagHeader, sessionETag(log))
+}
+
+// ifMatchVersion evaluates the If-Match header of a request for a session
+// and returns the version the change must be applied to. A missing header or
+// "*" imposes no version. Otherwise current loads the session and one of the
+// listed entity tags must be its ETag by strong comparison: weak tags never
+// match. ok is false if the precondition fails.
+func ifMatchVersion(header string, current func() (*models.ParkingSpaceLog, error)) (version *int64, ok bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil, true, nil
+	}
+
+	tags, valid := parseETags(header)
+	if !valid {
+		return nil, false, nil
+	}
+	log, err := current()
+	if err != nil {
+		return nil, false, err
+	}
+	etag := sessionETag(log)
+	for _, tag := range tags {
+		if tag == etag {
+			return &log.Version, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// sessionIfMatch evaluates the If-Match header of the request against the
+// current state of the session logID.
+func (h *Handlers) sessionIfMatch(c *gin.Context, logID string) (version *int64, ok bool, err error) {
+	return ifMatchVersion(c.GetHeader(IfMatchHeader), func() (*models.ParkingSpaceLog, error) {
+		return h.service.GetParkingSession(c.Request.Context(), logID)
+	})
+}
+
+// parseETags splits a list of entity tags such as "a", W/"b" into its
+// elements, keeping the W/ prefix of weak tags. ok is false if the list is
+// malformed.
+func parseETags(header string) (tags []string, ok bool) {
+	rest := strings.TrimSpace(header)
+	for rest != "" {
+		prefix := ""
+		if strings.HasPrefix(rest, "W/") {
+			prefix, rest = "W/", rest[2:]
+		}
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false
+		}
+		tags = append(tags, prefix+rest[:end+2])
+
+		rest = strings.TrimSpace(rest[end+2:])
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, false
+		}
+		rest = strings.TrimLeft(rest[1:], " \t,")
+	}
+	return tags, len(tags) > 0
+}
+
+// matchesIfNoneMatch reports whether the client already holds the current
+// representation of the session.
+func matchesIfNoneMatch(c *gin.Context, log *models.ParkingSpaceLog) bool {
+	return ifNoneMatch(c, sessionETag(log))
+}
+
+// ifNoneMatch reports whether the If-None-Match header of the request lists
+// etag.
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader(IfNoneMatchHeader)
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
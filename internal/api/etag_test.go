@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	overstayedAt := time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)
+	plain := &models.ParkingSpaceLog{LogID: "log-1", Version: 3}
+	flagged := &models.ParkingSpaceLog{LogID: "log-1", Version: 3, OverstayedAt: &overstayedAt}
+	errLoad := errors.New("load failed")
+
+	tests := []struct {
+		name        string
+		header      string
+		current     *models.ParkingSpaceLog
+		loadErr     error
+		wantVersion int64
+		wantAny     bool
+		wantOK      bool
+		wantErr     error
+	}{
+		{name: "no header", current: plain, wantAny: true, wantOK: true},
+		{name: "any", header: "*", current: plain, wantAny: true, wantOK: true},
+		{name: "current tag", header: `"log-1.3"`, current: plain, wantVersion: 3, wantOK: true},
+		{name: "current tag with marks", header: sessionETag(flagged), current: flagged, wantVersion: 3, wantOK: true},
+		{name: "tag without the new marks", header: `"log-1.3"`, current: flagged},
+		{name: "list with the current tag", header: `"log-1.2", "log-1.3"`, current: plain, wantVersion: 3, wantOK: true},
+		{name: "list without spaces", header: `"log-1.2","log-1.3"`, current: plain, wantVersion: 3, wantOK: true},
+		{name: "list without the current tag", header: `"log-1.1", "log-1.2"`, current: plain},
+		{name: "weak tag", header: `W/"log-1.3"`, current: plain},
+		{name: "weak and strong tags", header: `W/"log-1.2", "log-1.3"`, current: plain, wantVersion: 3, wantOK: true},
+		{name: "other session", header: `"log-2.3"`, current: plain},
+		{name: "unquoted", header: "log-1.3", current: plain},
+		{name: "unterminated", header: `"log-1.3`, current: plain},
+		{name: "missing comma", header: `"log-1.2" "log-1.3"`, current: plain},
+		{name: "load error", header: `"log-1.3"`, loadErr: errLoad, wantErr: errLoad},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok, err := ifMatchVersion(tt.header, func() (*models.ParkingSpaceLog, error) {
+				return tt.current, tt.loadErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if tt.wantAny {
+				if version != nil {
+					t.Errorf("version = %d, want none", *version)
+				}
+				return
+			}
+			if version == nil || *version != tt.wantVersion {
+				t.Errorf("version = %v, want %d", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSessionETagChangesWithMarks(t *testing.T) {
+	overstayedAt := time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)
+	plain := &models.ParkingSpaceLog{LogID: "log-1", Version: 3}
+	overstayed := &models.ParkingSpaceLog{LogID: "log-1", Version: 3, OverstayedAt: &overstayedAt}
+	outOfRange := &models.ParkingSpaceLog{LogID: "log-1", Version: 3, OutOfRangeAt: &overstayedAt}
+
+	tags := map[string]bool{}
+	for _, log := range []*models.ParkingSpaceLog{plain, overstayed, outOfRange} {
+		tags[sessionETag(log)] = true
+	}
+	if len(tags) != 3 {
+		t.Errorf("ETags %v are not distinct", tags)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,6 +20,7 @@ func NewHandlers(svc *service.Service) *Handlers {
 // @Summary      Получить количество свободных мест
 // @Description  Возвращает количество свободных парковочных мест
 // @Tags         parking
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -38,6 +40,7 @@ func (h *Handlers) GetCountOfFreeSpaces(c *gin.Context) {
 // @Summary      Получить список занятых мест
 // @Description  Возвращает список всех занятых парковочных мест
 // @Tags         parking
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -55,16 +58,22 @@ func (h *Handlers) GetOccupiedSpaces(c *gin.Context) {
 }
 
 // @Summary      Припарковать автомобиль
-// @Description  Занимает свободное парковочное место для автомобиля
+// @Description  Занимает свободное парковочное место для автомобиля. Автомобиль задаётся идентификатором vehicle_id или номером license_plate зарегистрированного автомобиля либо всеми данными автомобиля и водителя
 // @Tags         parking
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        request  body      AddParkingSpaceLogSchema  true  "Данные автомобиля"
-// @Success      200      {object}  models.ParkingSpaceLog
-// @Failure      400      {object}  map[string]string
-// @Failure      401      {object}  map[string]string
-// @Failure      500      {object}  map[string]string
+// @Param        Idempotency-Key  header    string                    false  "Ключ идемпотентности"
+// @Param        request          body      AddParkingSpaceLogSchema  true   "Данные автомобиля"
+// @Success      200              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      403              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      429              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
 // @Router       /parking/park-car [post]
 func (h *Handlers) ParkCar(c *gin.Context) {
 	var body AddParkingSpaceLogSchema
@@ -73,19 +82,24 @@ func (h *Handlers) ParkCar(c *gin.Context) {
 		return
 	}
 
-	log, err := h.service.AddParkingSpaceLog(
-		c.Request.Context(),
-		body.FirstName,
-		body.LastName,
-		body.CarMake,
-		body.LicensePlate,
-	)
+	log, err := h.service.AddParkingSpaceLog(c.Request.Context(), ClientID(c), body.parkRequest())
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "no free parking spaces available" {
+		switch {
+		case errors.Is(err, service.ErrNoFreeSpaces),
+			errors.Is(err, service.ErrCarDetailsRequired):
 			statusCode = http.StatusBadRequest
+		case errors.Is(err, service.ErrPlateDenied),
+			errors.Is(err, service.ErrPlateNotAllowed):
+			statusCode = http.StatusForbidden
+		case errors.Is(err, service.ErrVehicleNotFound):
+			statusCode = http.StatusUnprocessableEntity
+		case errors.Is(err, service.ErrSessionLimitReached):
+			statusCode = http.StatusTooManyRequests
+		case errors.Is(err, service.ErrPlaceOccupied):
+			statusCode = http.StatusConflict
 		}
-		c.JSON(statusCode, gin.H{"detail": err.Error()})
+		respondError(c, statusCode, err)
 		return
 	}
 
@@ -95,14 +109,17 @@ func (h *Handlers) ParkCar(c *gin.Context) {
 // @Summary      Освободить парковочное место
 // @Description  Освобождает указанное парковочное место
 // @Tags         parking
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
-// @Param        place_number  query     int  true  "Номер парковочного места"
-// @Success      200           {object}  models.ParkingSpaceLog
-// @Failure      400           {object}  map[string]string
-// @Failure      401           {object}  map[string]string
-// @Failure      500           {object}  map[string]string
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        place_number     query     int     true   "Номер парковочного места"
+// @Success      200              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
 // @Router       /parking/free-up [post]
 func (h *Handlers) FreeUpParkingSpace(c *gin.Context) {
 	placeNumberStr := c.Query("place_number")
@@ -115,19 +132,24 @@ func (h *Handlers) FreeUpParkingSpace(c *gin.Context) {
 	log, err := h.service.FreeUpParkingSpace(c.Request.Context(), placeNumber)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "parking space is already free" {
+		switch {
+		case errors.Is(err, service.ErrSpaceAlreadyFree):
 			statusCode = http.StatusBadRequest
+		case errors.Is(err, service.ErrSessionChanged):
+			statusCode = http.StatusConflict
 		}
 		c.JSON(statusCode, gin.H{"detail": err.Error()})
 		return
 	}
 
+	setSessionETag(c, log)
 	c.JSON(http.StatusOK, log)
 }
 
 // @Summary      Получить логи парковочных мест
 // @Description  Возвращает логи парковочных мест по имени и фамилии владельца
 // @Tags         parking
+// @Deprecated
 // @Accept       json
 // @Produce      json
 // @Security     ApiKeyAuth
@@ -159,3 +181,194 @@ func (h *Handlers) GetParkingSpaceLogs(c *gin.Context) {
 
 	c.JSON(http.StatusOK, logs)
 }
+
+// @Summary      Получить список превышений времени стоянки
+// @Description  Возвращает активные сессии, превысившие максимальное время стоянки для своего места
+// @Tags         parking
+// @Deprecated
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.ParkingSpaceLog
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /parking/overstays [get]
+func (h *Handlers) GetOverstays(c *gin.Context) {
+	sessions, err := h.service.GetOverstayedSessions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// @Summary      Получить парковочную сессию
+// @Description  Возвращает парковочную сессию по её идентификатору. Версия сессии передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304
+// @Tags         sessions
+// @Deprecated
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        log_id         path      string  true   "Идентификатор сессии"
+// @Param        If-None-Match  header    string  false  "ETag, полученный ранее"
+// @Success      200            {object}  models.ParkingSpaceLog
+// @Success      304            "Сессия не изменилась"
+// @Failure      401            {object}  map[string]string
+// @Failure      404            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /parking/sessions/{log_id} [get]
+func (h *Handlers) GetParkingSession(c *gin.Context) {
+	log, err := h.service.GetParkingSession(c.Request.Context(), c.Param("log_id"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrSessionNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"detail": err.Error()})
+		return
+	}
+
+	setSessionETag(c, log)
+	if matchesIfNoneMatch(c, log) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+// @Summary      Завершить парковочную сессию
+// @Description  Завершает активную парковочную сессию и освобождает занятое ею место. С заголовком If-Match сессия завершается, только если её ETag не изменился
+// @Tags         sessions
+// @Deprecated
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        If-Match         header    string  false  "ETag сессии или список ETag через запятую"
+// @Param        log_id           path      string  true   "Идентификатор сессии"
+// @Success      200              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      412              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /parking/sessions/{log_id}/end [post]
+func (h *Handlers) EndParkingSession(c *gin.Context) {
+	logID := c.Param("log_id")
+	expectedVersion, ok, err := h.sessionIfMatch(c, logID)
+	if err != nil {
+		respondError(c, sessionErrorStatus(err), err)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"detail": service.ErrPreconditionFailed.Error()})
+		return
+	}
+
+	log, err := h.service.EndParkingSession(c.Request.Context(), logID, expectedVersion)
+	if err != nil {
+		respondError(c, sessionErrorStatus(err), err)
+		return
+	}
+
+	setSessionETag(c, log)
+	c.JSON(http.StatusOK, log)
+}
+
+// @Summary      Получить чек парковочной сессии
+// @Description  Возвращает стоимость и длительность сессии. Для активной сессии чек предварительный и рассчитан на текущий момент
+// @Tags         sessions
+// @Deprecated
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        log_id  path      string  true  "Идентификатор сессии"
+// @Success      200     {object}  models.ParkingReceipt
+// @Failure      401     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /parking/sessions/{log_id}/receipt [get]
+func (h *Handlers) GetParkingReceipt(c *gin.Context) {
+	receipt, err := h.service.GetParkingReceipt(c.Request.Context(), c.Param("log_id"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, service.ErrSessionNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		c.JSON(statusCode, gin.H{"detail": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// @Summary      Переместить автомобиль на другое место
+// @Description  Атомарно освобождает текущее место сессии и занимает новое, сохраняя ту же сессию. Если номер места не указан, свободное место выбирается согласно стратегии распределения
+// @Tags         sessions
+// @Deprecated
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string                    false  "Ключ идемпотентности"
+// @Param        If-Match         header    string                    false  "ETag сессии или список ETag через запятую"
+// @Param        log_id           path      string                    true   "Идентификатор сессии"
+// @Param        request          body      MoveParkingSessionSchema  false  "Новое место"
+// @Success      200              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      412              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /parking/sessions/{log_id}/move [post]
+func (h *Handlers) MoveParkingSession(c *gin.Context) {
+	var body MoveParkingSessionSchema
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	logID := c.Param("log_id")
+	expectedVersion, ok, err := h.sessionIfMatch(c, logID)
+	if err != nil {
+		respondError(c, sessionErrorStatus(err), err)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"detail": service.ErrPreconditionFailed.Error()})
+		return
+	}
+
+	log, err := h.service.MoveParkingSpaceLog(c.Request.Context(), logID, body.PlaceNumber, expectedVersion)
+	if err != nil {
+		respondError(c, sessionErrorStatus(err), err)
+		return
+	}
+
+	setSessionETag(c, log)
+	c.JSON(http.StatusOK, log)
+}
+
+func sessionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSessionNotActive),
+		errors.Is(err, service.ErrPlaceOutOfRange),
+		errors.Is(err, service.ErrSessionAlreadyThere),
+		errors.Is(err, service.ErrNoFreeSpaces):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrPlaceOccupied),
+		errors.Is(err, service.ErrPlaceOutOfService),
+		errors.Is(err, service.ErrPlaceReserved),
+		errors.Is(err, service.ErrPermitRequired),
+		errors.Is(err, service.ErrSessionChanged):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/amend-parking-backend/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandlers struct {
+	checker *health.Checker
+}
+
+func NewHealthHandlers(checker *health.Checker) *HealthHandlers {
+	return &HealthHandlers{checker: checker}
+}
+
+// @Summary      Проверка жизнеспособности
+// @Description  Сообщает, что процесс запущен и обрабатывает запросы
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Router       /healthz [get]
+func (h *HealthHandlers) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, h.checker.Liveness())
+}
+
+// @Summary      Проверка готовности
+// @Description  Проверяет доступность MongoDB, создание индексов и корректность конфигурации. Во время остановки сервиса возвращает 503
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report
+// @Failure      503  {object}  health.Report
+// @Router       /readyz [get]
+func (h *HealthHandlers) Readiness(c *gin.Context) {
+	report := h.checker.Readiness(c.Request.Context())
+	statusCode := http.StatusOK
+	if report.Status != health.StatusOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, report)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// transientErrorKey marks a response caused by an error that may clear
+	// up by itself, such as a full lot.
+	transientErrorKey = "transient_error"
+)
+
+// transientErrors are the errors whose responses are not stored for replay:
+// retrying the same request later may well succeed.
+var transientErrors = []error{
+	service.ErrNoFreeSpaces,
+	service.ErrSessionLimitReached,
+}
+
+// Idempotency replays the stored response for mutating requests retried with
+// the same Idempotency-Key header instead of executing them again. Keys are
+// scoped to the client, method and path, so that clients cannot collide with
+// or replay each other's requests; a key reused for the same endpoint with a
+// different query or body is rejected with 422. It must run after the
+// authentication middleware so that the client is known. A key whose request
+// failed with a server error, a panic, 429 or another transient error such as
+// a full lot is released for a retry instead of replaying the failure. The
+// key is completed or released even if the client has gone away.
+func Idempotency(svc *service.Service, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"detail": "Idempotency-Key header must not be longer than 255 characters.",
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = scopeIdempotencyKey(ClientID(c), c.Request, key)
+		stored, err := svc.BeginIdempotentRequest(c.Request.Context(), key, hashRequest(c.Request, body))
+		if err != nil {
+			statusCode := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, service.ErrIdempotencyKeyReused):
+				statusCode = http.StatusUnprocessableEntity
+			case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+				statusCode = http.StatusConflict
+			}
+			c.JSON(statusCode, gin.H{"detail": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if stored != nil {
+			c.Header(IdempotentReplayedHeader, "true")
+			if stored.Location != "" {
+				c.Header("Location", stored.Location)
+			}
+			c.Data(stored.StatusCode, stored.ContentType, stored.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		ctx := context.WithoutCancel(c.Request.Context())
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := svc.AbortIdempotentRequest(ctx, key); err != nil {
+					logger.ErrorContext(c.Request.Context(), "Error releasing idempotency key", "error", err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if !replayable(c, recorder.Status()) {
+			if err := svc.AbortIdempotentRequest(ctx, key); err != nil {
+				logger.ErrorContext(ctx, "Error releasing idempotency key", "error", err)
+			}
+			return
+		}
+
+		err = svc.CompleteIdempotentRequest(
+			ctx,
+			key,
+			recorder.Status(),
+			recorder.Header().Get("Content-Type"),
+			recorder.Header().Get("Location"),
+			recorder.body.Bytes(),
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error storing idempotent response", "error", err)
+		}
+	}
+}
+
+// replayable reports whether the response may be stored and replayed to
+// retries: server errors, rate limiting and transient errors are not.
+func replayable(c *gin.Context, statusCode int) bool {
+	if statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return !c.GetBool(transientErrorKey)
+}
+
+func isTransientError(err error) bool {
+	for _, transient := range transientErrors {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// scopeIdempotencyKey derives the stored key from the Idempotency-Key header
+// and the client, method and path of the request.
+func scopeIdempotencyKey(clientID string, r *http.Request, key string) string {
+	hash := sha256.New()
+	hash.Write([]byte(clientID + "\n" + r.Method + " " + r.URL.Path + "\n" + key))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func hashRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written by the handler.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestScopeIdempotencyKey(t *testing.T) {
+	base := scopeIdempotencyKey("key:a", httptest.NewRequest("POST", "/api/v1/sessions", nil), "k1")
+
+	tests := []struct {
+		name     string
+		clientID string
+		method   string
+		target   string
+		key      string
+		wantSame bool
+	}{
+		{name: "same request", clientID: "key:a", method: "POST", target: "/api/v1/sessions", key: "k1", wantSame: true},
+		{name: "different query", clientID: "key:a", method: "POST", target: "/api/v1/sessions?x=1", key: "k1", wantSame: true},
+		{name: "other client", clientID: "key:b", method: "POST", target: "/api/v1/sessions", key: "k1"},
+		{name: "other method", clientID: "key:a", method: "PUT", target: "/api/v1/sessions", key: "k1"},
+		{name: "other path", clientID: "key:a", method: "POST", target: "/api/v1/blocks", key: "k1"},
+		{name: "other key", clientID: "key:a", method: "POST", target: "/api/v1/sessions", key: "k2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scopeIdempotencyKey(tt.clientID, httptest.NewRequest(tt.method, tt.target, nil), tt.key)
+			if (got == base) != tt.wantSame {
+				t.Errorf("scoped key equal to the base = %v, want %v", got == base, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestReplayable(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		transient  bool
+		want       bool
+	}{
+		{name: "success", statusCode: http.StatusCreated, want: true},
+		{name: "client error", statusCode: http.StatusUnprocessableEntity, want: true},
+		{name: "conflict", statusCode: http.StatusConflict, want: true},
+		{name: "transient conflict", statusCode: http.StatusConflict, transient: true},
+		{name: "transient bad request", statusCode: http.StatusBadRequest, transient: true},
+		{name: "too many requests", statusCode: http.StatusTooManyRequests},
+		{name: "server error", statusCode: http.StatusInternalServerError},
+		{name: "unavailable", statusCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			if tt.transient {
+				c.Set(transientErrorKey, true)
+			}
+			if got := replayable(c, tt.statusCode); got != tt.want {
+				t.Errorf("replayable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondErrorMarksTransientErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no free spaces", err: service.ErrNoFreeSpaces, want: true},
+		{name: "wrapped session limit", err: fmt.Errorf("park: %w", service.ErrSessionLimitReached), want: true},
+		{name: "place occupied", err: service.ErrPlaceOccupied},
+		{name: "plate denied", err: service.ErrPlateDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			respondError(c, v1ErrorStatus(tt.err), tt.err)
+			if got := c.GetBool(transientErrorKey); got != tt.want {
+				t.Errorf("marked transient = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
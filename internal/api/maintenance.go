@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Вывести место из эксплуатации
+// @Description  Создаёт блокировку места на время обслуживания (например, уборки снега или ремонта). С момента from (по умолчанию — сразу) и до until (по умолчанию — до завершения блокировки) место не выдаётся новым автомобилям и не считается свободным. Автомобиль, уже стоящий на месте, остаётся на нём. По истечении until блокировка снимается автоматически. Адрес блокировки передаётся в заголовке Location
+// @Tags         maintenance
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string                        false  "Ключ идемпотентности"
+// @Param        request          body      CreateMaintenanceBlockSchema  true   "Место, причина и период блокировки"
+// @Success      201              {object}  models.MaintenanceBlock
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/maintenance-blocks [post]
+func (h *Handlers) CreateMaintenanceBlock(c *gin.Context) {
+	var body CreateMaintenanceBlockSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	block, err := h.service.CreateMaintenanceBlock(
+		c.Request.Context(),
+		ClientID(c),
+		body.PlaceNumber,
+		body.Reason,
+		body.From,
+		body.Until,
+	)
+	if errors.Is(err, service.ErrPlaceOutOfRange) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+		return
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	c.Header("Location", V1Prefix+"/maintenance-blocks/"+block.BlockID)
+	c.JSON(http.StatusCreated, block)
+}
+
+// @Summary      Список блокировок мест
+// @Description  Возвращает блокировки мест, начиная с последних. С active=true — только запланированные и действующие
+// @Tags         maintenance
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        active  query     bool  false  "Только запланированные и действующие блокировки"
+// @Success      200     {array}   models.MaintenanceBlock
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/maintenance-blocks [get]
+func (h *Handlers) ListMaintenanceBlocks(c *gin.Context) {
+	active, err := strconv.ParseBool(c.DefaultQuery("active", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid active"})
+		return
+	}
+
+	blocks, err := h.service.GetMaintenanceBlocks(c.Request.Context(), active)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(blocks))
+}
+
+// @Summary      Получить блокировку места
+// @Description  Возвращает блокировку места и её состояние: scheduled, active, expired или ended
+// @Tags         maintenance
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        block_id  path      string  true  "Идентификатор блокировки"
+// @Success      200       {object}  models.MaintenanceBlock
+// @Failure      401       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /api/v1/maintenance-blocks/{block_id} [get]
+func (h *Handlers) GetMaintenanceBlock(c *gin.Context) {
+	block, err := h.service.GetMaintenanceBlock(c.Request.Context(), c.Param("block_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, block)
+}
+
+// @Summary      Завершить блокировку места
+// @Description  Возвращает место в эксплуатацию. Запланированная блокировка отменяется
+// @Tags         maintenance
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        block_id         path      string  true   "Идентификатор блокировки"
+// @Success      204              "Блокировка завершена"
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/maintenance-blocks/{block_id} [delete]
+func (h *Handlers) EndMaintenanceBlock(c *gin.Context) {
+	if err := h.service.EndMaintenanceBlock(c.Request.Context(), c.Param("block_id"), ClientID(c)); err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Выдать пропуск
+// @Description  Выдаёт пропуск зарегистрированному автомобилю (vehicle_id) или водителю (driver_id) на период с valid_from (по умолчанию — сразу) до valid_until. Владелец пропуска паркуется без оплаты, получает выделенное место place_number, пока оно свободно, и допускается в зоны пропусков из zones (по умолчанию — во все зоны PERMIT_ZONES). Адрес пропуска передаётся в заголовке Location
+// @Tags         permits
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string              false  "Ключ идемпотентности"
+// @Param        request          body      CreatePermitSchema  true   "Владелец, период, зоны и выделенное место"
+// @Success      201              {object}  models.Permit
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/permits [post]
+func (h *Handlers) CreatePermit(c *gin.Context) {
+	var body CreatePermitSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	permit, err := h.service.CreatePermit(c.Request.Context(), ClientID(c), body.details())
+	if errors.Is(err, service.ErrLotNotFound) ||
+		errors.Is(err, service.ErrVehicleNotFound) ||
+		errors.Is(err, service.ErrDriverNotFound) ||
+		errors.Is(err, service.ErrPlaceOutOfRange) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+		return
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	c.Header("Location", V1Prefix+"/permits/"+permit.PermitID)
+	c.JSON(http.StatusCreated, permit)
+}
+
+// @Summary      Список пропусков
+// @Description  Возвращает пропуска, начиная с последних. С active=true — только запланированные и действующие
+// @Tags         permits
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        active  query     bool  false  "Только запланированные и действующие пропуска"
+// @Success      200     {array}   models.Permit
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/permits [get]
+func (h *Handlers) ListPermits(c *gin.Context) {
+	active, err := strconv.ParseBool(c.DefaultQuery("active", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid active"})
+		return
+	}
+
+	permits, err := h.service.GetPermits(c.Request.Context(), active)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(permits))
+}
+
+// @Summary      Истекающие пропуска
+// @Description  Возвращает неотозванные пропуска, срок которых истекает в течение within (по умолчанию — PERMIT_EXPIRY_NOTICE), начиная с ближайших
+// @Tags         permits
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        within  query     string  false  "Период в нотации Go, например 72h"
+// @Success      200     {array}   models.Permit
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/permits/expiring [get]
+func (h *Handlers) ListExpiringPermits(c *gin.Context) {
+	var within time.Duration
+	if value := c.Query("within"); value != "" {
+		var err error
+		within, err = time.ParseDuration(value)
+		if err != nil || within <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid within"})
+			return
+		}
+	}
+
+	permits, err := h.service.GetExpiringPermits(c.Request.Context(), within)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(permits))
+}
+
+// @Summary      Получить пропуск
+// @Description  Возвращает пропуск и его состояние: scheduled, active, expired или revoked
+// @Tags         permits
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        permit_id  path      string  true  "Идентификатор пропуска"
+// @Success      200        {object}  models.Permit
+// @Failure      401        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /api/v1/permits/{permit_id} [get]
+func (h *Handlers) GetPermit(c *gin.Context) {
+	permit, err := h.service.GetPermit(c.Request.Context(), c.Param("permit_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, permit)
+}
+
+// @Summary      Отозвать пропуск
+// @Description  Досрочно прекращает действие пропуска и освобождает его выделенное место. Автомобиль, уже припаркованный по пропуску, остаётся на месте, и текущая сессия не оплачивается
+// @Tags         permits
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        permit_id        path      string  true   "Идентификатор пропуска"
+// @Success      204              "Пропуск отозван"
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/permits/{permit_id} [delete]
+func (h *Handlers) RevokePermit(c *gin.Context) {
+	if err := h.service.RevokePermit(c.Request.Context(), c.Param("permit_id"), ClientID(c)); err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/metrics"
+	"github.com/amend-parking-backend/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	rateLimitClassRead     = "read"
+	rateLimitClassMutation = "mutation"
+)
+
+// rateLimitResultKey holds the tightest bucket counted so far, so that the
+// later rate limiter only replaces the headers when its bucket is tighter.
+const rateLimitResultKey = "rate_limit_result"
+
+// RateLimitIP applies token buckets per client IP, with separate budgets for
+// reads and mutations. It runs before authentication so that requests with
+// a wrong key are limited as well. The client IP is only taken from
+// X-Forwarded-For behind a proxy listed in TRUSTED_PROXIES.
+func RateLimitIP(cfg config.RateLimitConfig, m *metrics.Metrics) gin.HandlerFunc {
+	return rateLimit("ip",
+		ratelimit.New(ratelimit.PerMinute(cfg.ReadsPerMinute, cfg.ReadBurst), cfg.MaxClients),
+		ratelimit.New(ratelimit.PerMinute(cfg.MutationsPerMinute, cfg.MutationBurst), cfg.MaxClients),
+		func(c *gin.Context) string { return "ip:" + c.ClientIP() },
+		m,
+	)
+}
+
+// RateLimitClient applies token buckets per client, with its own budgets:
+// all instances of an integration share one API key, so the client budget
+// is larger than the per IP one. It must run after the authentication
+// middleware so that the client is known.
+func RateLimitClient(cfg config.RateLimitConfig, m *metrics.Metrics) gin.HandlerFunc {
+	return rateLimit("client",
+		ratelimit.New(ratelimit.PerMinute(cfg.ClientReadsPerMinute, cfg.ClientReadBurst), cfg.MaxClients),
+		ratelimit.New(ratelimit.PerMinute(cfg.ClientMutationsPerMinute, cfg.ClientMutationBurst), cfg.MaxClients),
+		ClientID,
+		m,
+	)
+}
+
+// rateLimit counts the request in the bucket of key. Every response carries
+// the RateLimit-* headers of the most restrictive bucket; rejected requests
+// get 429 with Retry-After.
+func rateLimit(scope string, reads, mutations *ratelimit.Limiter, key func(*gin.Context) string, m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter, class := reads, rateLimitClassRead
+		if isMutatingMethod(c.Request.Method) {
+			limiter, class = mutations, rateLimitClassMutation
+		}
+
+		bucket := key(c)
+		if bucket == "" {
+			c.Next()
+			return
+		}
+
+		result := limiter.Allow(bucket)
+		if !result.Allowed {
+			m.RateLimitedTotal.WithLabelValues(class, scope).Inc()
+			setRateLimitHeaders(c, result)
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"detail": "Too many requests, retry later.",
+			})
+			c.Abort()
+			return
+		}
+
+		if result.Limit > 0 {
+			tightest, ok := c.Get(rateLimitResultKey)
+			if !ok || result.Remaining < tightest.(ratelimit.Result).Remaining {
+				c.Set(rateLimitResultKey, result)
+				setRateLimitHeaders(c, result)
+			}
+		}
+		c.Next()
+	}
+}
+
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
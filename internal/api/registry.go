@@ -0,0 +1,290 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Зарегистрировать водителя
+// @Description  Добавляет водителя в реестр. Адрес водителя передаётся в заголовке Location
+// @Tags         registry
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string        false  "Ключ идемпотентности"
+// @Param        request          body      DriverSchema  true   "Данные водителя"
+// @Success      201              {object}  models.Driver
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/drivers [post]
+func (h *Handlers) CreateDriver(c *gin.Context) {
+	var body DriverSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	driver, err := h.service.CreateDriver(c.Request.Context(), body.details())
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Header("Location", V1Prefix+"/drivers/"+driver.DriverID)
+	c.JSON(http.StatusCreated, driver)
+}
+
+// @Summary      Список водителей
+// @Description  Возвращает зарегистрированных водителей по алфавиту
+// @Tags         registry
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   models.Driver
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/v1/drivers [get]
+func (h *Handlers) ListDrivers(c *gin.Context) {
+	drivers, err := h.service.GetDrivers(c.Request.Context())
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(drivers))
+}
+
+// @Summary      Получить водителя
+// @Tags         registry
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        driver_id  path      string  true  "Идентификатор водителя"
+// @Success      200        {object}  models.Driver
+// @Failure      401        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /api/v1/drivers/{driver_id} [get]
+func (h *Handlers) GetDriver(c *gin.Context) {
+	driver, err := h.service.GetDriver(c.Request.Context(), c.Param("driver_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, driver)
+}
+
+// @Summary      Изменить водителя
+// @Description  Заменяет данные водителя. Начатые ранее сессии сохраняют прежние данные
+// @Tags         registry
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string        false  "Ключ идемпотентности"
+// @Param        driver_id        path      string        true   "Идентификатор водителя"
+// @Param        request          body      DriverSchema  true   "Данные водителя"
+// @Success      200              {object}  models.Driver
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/drivers/{driver_id} [put]
+func (h *Handlers) UpdateDriver(c *gin.Context) {
+	var body DriverSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	driver, err := h.service.UpdateDriver(c.Request.Context(), c.Param("driver_id"), body.details())
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, driver)
+}
+
+// @Summary      Удалить водителя
+// @Description  Удаляет водителя из реестра. Водителя с зарегистрированными автомобилями удалить нельзя
+// @Tags         registry
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        driver_id        path      string  true   "Идентификатор водителя"
+// @Success      204              "Водитель удалён"
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/drivers/{driver_id} [delete]
+func (h *Handlers) DeleteDriver(c *gin.Context) {
+	if err := h.service.DeleteDriver(c.Request.Context(), c.Param("driver_id")); err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Автомобили водителя
+// @Tags         registry
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        driver_id  path      string  true  "Идентификатор водителя"
+// @Success      200        {array}   models.Vehicle
+// @Failure      401        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      500        {object}  map[string]string
+// @Router       /api/v1/drivers/{driver_id}/vehicles [get]
+func (h *Handlers) ListDriverVehicles(c *gin.Context) {
+	vehicles, err := h.service.GetVehicles(c.Request.Context(), c.Param("driver_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(vehicles))
+}
+
+// @Summary      Зарегистрировать автомобиль
+// @Description  Добавляет автомобиль водителя в реестр. Номер хранится в нормализованном виде: заглавными буквами, без пробелов и дефисов, с латинскими буквами, похожими на кириллические, заменёнными на кириллические. Адрес автомобиля передаётся в заголовке Location
+// @Tags         registry
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string         false  "Ключ идемпотентности"
+// @Param        request          body      VehicleSchema  true   "Данные автомобиля"
+// @Success      201              {object}  models.Vehicle
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/vehicles [post]
+func (h *Handlers) CreateVehicle(c *gin.Context) {
+	var body VehicleSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	vehicle, err := h.service.CreateVehicle(c.Request.Context(), body.details())
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.Header("Location", V1Prefix+"/vehicles/"+vehicle.VehicleID)
+	c.JSON(http.StatusCreated, vehicle)
+}
+
+// @Summary      Список автомобилей
+// @Description  Возвращает зарегистрированные автомобили; с license_plate — только автомобиль с этим номером в любом написании
+// @Tags         registry
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        license_plate  query     string  false  "Номер автомобиля"
+// @Success      200            {array}   models.Vehicle
+// @Failure      401            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /api/v1/vehicles [get]
+func (h *Handlers) ListVehicles(c *gin.Context) {
+	if licensePlate := c.Query("license_plate"); licensePlate != "" {
+		vehicle, err := h.service.GetVehicleByLicensePlate(c.Request.Context(), licensePlate)
+		if errors.Is(err, service.ErrVehicleNotFound) {
+			c.JSON(http.StatusOK, []models.Vehicle{})
+			return
+		}
+		if err != nil {
+			v1Error(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, []models.Vehicle{*vehicle})
+		return
+	}
+
+	vehicles, err := h.service.GetVehicles(c.Request.Context(), "")
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(vehicles))
+}
+
+// @Summary      Получить автомобиль
+// @Tags         registry
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        vehicle_id  path      string  true  "Идентификатор автомобиля"
+// @Success      200         {object}  models.Vehicle
+// @Failure      401         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Router       /api/v1/vehicles/{vehicle_id} [get]
+func (h *Handlers) GetVehicle(c *gin.Context) {
+	vehicle, err := h.service.GetVehicle(c.Request.Context(), c.Param("vehicle_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, vehicle)
+}
+
+// @Summary      Изменить автомобиль
+// @Description  Заменяет данные автомобиля, в том числе его владельца. Начатые ранее сессии сохраняют прежние данные
+// @Tags         registry
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string         false  "Ключ идемпотентности"
+// @Param        vehicle_id       path      string         true   "Идентификатор автомобиля"
+// @Param        request          body      VehicleSchema  true   "Данные автомобиля"
+// @Success      200              {object}  models.Vehicle
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/vehicles/{vehicle_id} [put]
+func (h *Handlers) UpdateVehicle(c *gin.Context) {
+	var body VehicleSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	vehicle, err := h.service.UpdateVehicle(c.Request.Context(), c.Param("vehicle_id"), body.details())
+	if err != nil {
+		vehicleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, vehicle)
+}
+
+// @Summary      Удалить автомобиль
+// @Description  Удаляет автомобиль из реестра. Его сессии сохраняют данные автомобиля
+// @Tags         registry
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        vehicle_id       path      string  true   "Идентификатор автомобиля"
+// @Success      204              "Автомобиль удалён"
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/vehicles/{vehicle_id} [delete]
+func (h *Handlers) DeleteVehicle(c *gin.Context) {
+	if err := h.service.DeleteVehicle(c.Request.Context(), c.Param("vehicle_id")); err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// vehicleError answers a failed vehicle write. The driver is referenced by
+// the body, so a missing one makes the request unprocessable rather than the
+// resource not found.
+func vehicleError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrDriverNotFound) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+		return
+	}
+	v1Error(c, err)
+}
@@ -1,27 +1,126 @@
 package api
 
 import (
+	"log/slog"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/database"
+	"github.com/amend-parking-backend/internal/health"
+	"github.com/amend-parking-backend/internal/metrics"
 	"github.com/amend-parking-backend/internal/service"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRoutes(router *gin.Engine, svc *service.Service) {
+func SetupRoutes(
+	router *gin.Engine,
+	cfg *config.Config,
+	svc *service.Service,
+	db *database.Database,
+	checker *health.Checker,
+	m *metrics.Metrics,
+	reloader Reloader,
+	logger *slog.Logger,
+) {
 	handlers := NewHandlers(svc)
+	healthHandlers := NewHealthHandlers(checker)
+	adminHandlers := NewAdminHandlers(reloader, svc)
+
+	router.Use(
+		otelgin.Middleware(cfg.TracingServiceName),
+		RequestID(),
+		RequestLogger(logger),
+		Recovery(logger),
+		RequestMetrics(m),
+	)
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	router.GET("/healthz", healthHandlers.Liveness)
+	router.GET("/readyz", healthHandlers.Readiness)
 
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.GET("/docs", func(c *gin.Context) {
 		c.Redirect(302, "/docs/index.html")
 	})
 
+	// The per IP limit runs before authentication so that guessing keys is
+	// limited too, and is shared with the admin routes. The legacy routes
+	// share the rate limits with the versioned API.
+	ipRateLimit := RateLimitIP(cfg.RateLimit, m)
+	protected := []gin.HandlerFunc{
+		ipRateLimit,
+		Authenticate(cfg.ParkingServiceAPIKey, cfg.TLS.ClientIdentities),
+		RateLimitClient(cfg.RateLimit, m),
+		RequireDatabase(db),
+		Idempotency(svc, logger),
+	}
+
+	v1 := router.Group(V1Prefix)
+	v1.Use(protected...)
+	{
+		v1.GET("/lots", handlers.ListLots)
+		v1.GET("/lots/:lot_id", handlers.GetLot)
+		v1.GET("/lots/:lot_id/availability", handlers.GetLotAvailability)
+		v1.GET("/lots/:lot_id/places", handlers.GetLotPlaces)
+		v1.GET("/spaces", handlers.ListSpaces)
+		v1.GET("/spaces/:place_number", handlers.GetSpace)
+		v1.DELETE("/spaces/:place_number/session", handlers.ReleaseSpace)
+		v1.GET("/sessions", handlers.ListSessions)
+		v1.POST("/sessions", handlers.CreateSession)
+		v1.GET("/sessions/:log_id", handlers.GetSession)
+		v1.PATCH("/sessions/:log_id", handlers.UpdateSession)
+		v1.DELETE("/sessions/:log_id", handlers.DeleteSession)
+		v1.GET("/sessions/:log_id/receipt", handlers.GetSessionReceipt)
+		v1.GET("/maintenance-blocks", handlers.ListMaintenanceBlocks)
+		v1.POST("/maintenance-blocks", handlers.CreateMaintenanceBlock)
+		v1.GET("/maintenance-blocks/:block_id", handlers.GetMaintenanceBlock)
+		v1.DELETE("/maintenance-blocks/:block_id", handlers.EndMaintenanceBlock)
+		v1.GET("/permits", handlers.ListPermits)
+		v1.POST("/permits", handlers.CreatePermit)
+		v1.GET("/permits/expiring", handlers.ListExpiringPermits)
+		v1.GET("/permits/:permit_id", handlers.GetPermit)
+		v1.DELETE("/permits/:permit_id", handlers.RevokePermit)
+		v1.GET("/drivers", handlers.ListDrivers)
+		v1.POST("/drivers", handlers.CreateDriver)
+		v1.GET("/drivers/:driver_id", handlers.GetDriver)
+		v1.PUT("/drivers/:driver_id", handlers.UpdateDriver)
+		v1.DELETE("/drivers/:driver_id", handlers.DeleteDriver)
+		v1.GET("/drivers/:driver_id/vehicles", handlers.ListDriverVehicles)
+		v1.GET("/vehicles", handlers.ListVehicles)
+		v1.POST("/vehicles", handlers.CreateVehicle)
+		v1.GET("/vehicles/:vehicle_id", handlers.GetVehicle)
+		v1.PUT("/vehicles/:vehicle_id", handlers.UpdateVehicle)
+		v1.DELETE("/vehicles/:vehicle_id", handlers.DeleteVehicle)
+	}
+
 	parking := router.Group("/parking")
-	parking.Use(APIKeyAuth())
+	parking.Use(Deprecated(legacyAPIDeprecatedAt, cfg.LegacyAPISunset, V1Prefix))
+	parking.Use(protected...)
 	{
 		parking.GET("/free-spaces-count", handlers.GetCountOfFreeSpaces)
 		parking.GET("/occupied-spaces-list", handlers.GetOccupiedSpaces)
 		parking.POST("/park-car", handlers.ParkCar)
 		parking.POST("/free-up", handlers.FreeUpParkingSpace)
 		parking.GET("/parking-space-logs", handlers.GetParkingSpaceLogs)
+		parking.GET("/overstays", handlers.GetOverstays)
+		parking.GET("/sessions/:log_id", handlers.GetParkingSession)
+		parking.POST("/sessions/:log_id/end", handlers.EndParkingSession)
+		parking.GET("/sessions/:log_id/receipt", handlers.GetParkingReceipt)
+		parking.POST("/sessions/:log_id/move", handlers.MoveParkingSession)
+	}
+
+	admin := router.Group("/admin")
+	admin.Use(ipRateLimit, AuthenticateAdmin(cfg.AdminAPIKey, cfg.TLS.ClientIdentities, cfg.TLS.AdminIdentities), RequireDatabase(db))
+	{
+		admin.POST("/reload", adminHandlers.ReloadConfig)
+		admin.GET("/capacity", adminHandlers.GetCapacity)
+		admin.PUT("/capacity", adminHandlers.SetCapacity)
+		admin.GET("/access-policy", adminHandlers.GetAccessPolicy)
+		admin.PUT("/access-policy", adminHandlers.SetAccessPolicy)
+		admin.GET("/plate-lists/:list", adminHandlers.GetPlateList)
+		admin.POST("/plate-lists/:list", adminHandlers.AddPlateListEntry)
+		admin.DELETE("/plate-lists/:list/:license_plate", adminHandlers.RemovePlateListEntry)
+		admin.GET("/audit-log", adminHandlers.GetAuditLog)
 	}
 }
@@ -1,8 +1,114 @@
 package api
 
+import (
+	"time"
+
+	"github.com/amend-parking-backend/internal/service"
+)
+
+// AddParkingSpaceLogSchema identifies the car to park either by vehicle_id
+// or license_plate of a registered vehicle, or by all of its details.
 type AddParkingSpaceLogSchema struct {
-	FirstName    string `json:"first_name" binding:"required" example:"Иван"`
-	LastName     string `json:"last_name" binding:"required" example:"Иванов"`
-	CarMake      string `json:"car_make" binding:"required" example:"Toyota"`
+	VehicleID    string `json:"vehicle_id" example:"c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"`
+	FirstName    string `json:"first_name" example:"Иван"`
+	LastName     string `json:"last_name" example:"Иванов"`
+	CarMake      string `json:"car_make" example:"Toyota"`
+	LicensePlate string `json:"license_plate" example:"А123БВ777"`
+}
+
+func (s AddParkingSpaceLogSchema) parkRequest() service.ParkRequest {
+	return service.ParkRequest{
+		VehicleID:    s.VehicleID,
+		FirstName:    s.FirstName,
+		LastName:     s.LastName,
+		CarMake:      s.CarMake,
+		LicensePlate: s.LicensePlate,
+	}
+}
+
+type DriverSchema struct {
+	FirstName string `json:"first_name" binding:"required" example:"Иван"`
+	LastName  string `json:"last_name" binding:"required" example:"Иванов"`
+	Phone     string `json:"phone" example:"+79991234567"`
+}
+
+func (s DriverSchema) details() service.DriverDetails {
+	return service.DriverDetails{FirstName: s.FirstName, LastName: s.LastName, Phone: s.Phone}
+}
+
+type VehicleSchema struct {
+	DriverID     string `json:"driver_id" binding:"required" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
 	LicensePlate string `json:"license_plate" binding:"required" example:"А123БВ777"`
+	Make         string `json:"make" binding:"required" example:"Toyota"`
+	Model        string `json:"model" example:"Camry"`
+	Colour       string `json:"colour" example:"белый"`
+	SizeClass    string `json:"size_class" binding:"omitempty,oneof=small medium large" example:"medium" enums:"small,medium,large"`
+}
+
+func (s VehicleSchema) details() service.VehicleDetails {
+	return service.VehicleDetails{
+		DriverID:     s.DriverID,
+		LicensePlate: s.LicensePlate,
+		Make:         s.Make,
+		Model:        s.Model,
+		Colour:       s.Colour,
+		SizeClass:    s.SizeClass,
+	}
+}
+
+type MoveParkingSessionSchema struct {
+	PlaceNumber *int `json:"place_number" binding:"omitempty,min=1" example:"7"`
+}
+
+type CreateMaintenanceBlockSchema struct {
+	PlaceNumber int        `json:"place_number" binding:"required,min=1" example:"7"`
+	Reason      string     `json:"reason" binding:"required" example:"Уборка снега"`
+	From        *time.Time `json:"from" example:"2024-01-01T08:00:00Z"`
+	Until       *time.Time `json:"until" example:"2024-01-01T12:00:00Z"`
+}
+
+type CreatePermitSchema struct {
+	VehicleID   string     `json:"vehicle_id" binding:"required_without=DriverID,excluded_with=DriverID" example:"c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"`
+	DriverID    string     `json:"driver_id" binding:"required_without=VehicleID" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
+	LotID       string     `json:"lot_id" example:"main"`
+	ValidFrom   *time.Time `json:"valid_from" example:"2024-01-01T00:00:00Z"`
+	ValidUntil  time.Time  `json:"valid_until" binding:"required" example:"2024-02-01T00:00:00Z"`
+	Zones       []string   `json:"zones" example:"A"`
+	PlaceNumber *int       `json:"place_number" binding:"omitempty,min=1" example:"7"`
+}
+
+func (s CreatePermitSchema) details() service.PermitDetails {
+	return service.PermitDetails{
+		LotID:       s.LotID,
+		VehicleID:   s.VehicleID,
+		DriverID:    s.DriverID,
+		ValidFrom:   s.ValidFrom,
+		ValidUntil:  s.ValidUntil,
+		Zones:       s.Zones,
+		PlaceNumber: s.PlaceNumber,
+	}
+}
+
+type ReloadConfigResponse struct {
+	Changed         []string `json:"changed" example:"PARKING_SLOTS_COUNT"`
+	RestartRequired []string `json:"restart_required" example:"MONGODB_URL"`
+	ShadowedByEnv   []string `json:"shadowed_by_env" example:"PARKING_HOURLY_RATE"`
+}
+
+type ConfigErrorResponse struct {
+	Detail string   `json:"detail" example:"invalid configuration"`
+	Errors []string `json:"errors" example:"PARKING_SLOTS_COUNT must be positive, got 0"`
+}
+
+type SetAccessModeSchema struct {
+	Mode string `json:"mode" binding:"required" example:"allow_list" enums:"open,allow_list"`
+}
+
+type AddPlateListEntrySchema struct {
+	LicensePlate string `json:"license_plate" binding:"required" example:"А123ВС777"`
+	Reason       string `json:"reason" example:"Неоплаченные штрафы"`
+}
+
+type SetCapacitySchema struct {
+	SlotsCount int `json:"slots_count" binding:"required,min=1" example:"40"`
 }
@@ -0,0 +1,495 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/amend-parking-backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// V1Prefix is the path of the versioned API.
+const V1Prefix = "/api/v1"
+
+// v1ErrorStatus maps service errors to the status codes of the versioned
+// API: missing resources are 404 and requests that conflict with the current
+// state of the lot or the session are 409.
+func v1ErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrLotNotFound),
+		errors.Is(err, service.ErrSessionNotFound),
+		errors.Is(err, service.ErrBlockNotFound),
+		errors.Is(err, service.ErrDriverNotFound),
+		errors.Is(err, service.ErrVehicleNotFound),
+		errors.Is(err, service.ErrPermitNotFound),
+		errors.Is(err, service.ErrPlaceOutOfRange):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrNoFreeSpaces),
+		errors.Is(err, service.ErrSessionNotActive),
+		errors.Is(err, service.ErrSessionAlreadyThere),
+		errors.Is(err, service.ErrPlaceOccupied),
+		errors.Is(err, service.ErrPlaceOutOfService),
+		errors.Is(err, service.ErrSessionChanged),
+		errors.Is(err, service.ErrBlockFinished),
+		errors.Is(err, service.ErrDriverHasVehicles),
+		errors.Is(err, service.ErrPlateRegistered),
+		errors.Is(err, service.ErrPermitFinished),
+		errors.Is(err, service.ErrPlaceReserved),
+		errors.Is(err, service.ErrPermitRequired):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrCarDetailsRequired):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrInvalidBlockPeriod),
+		errors.Is(err, service.ErrInvalidPermitPeriod),
+		errors.Is(err, service.ErrUnknownPermitZone):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrPlateDenied),
+		errors.Is(err, service.ErrPlateNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrSessionLimitReached):
+		return http.StatusTooManyRequests
+	}
+	return http.StatusInternalServerError
+}
+
+func v1Error(c *gin.Context, err error) {
+	respondError(c, v1ErrorStatus(err), err)
+}
+
+// respondError writes err with its code and marks transient errors for the
+// Idempotency middleware, so that a retry is executed again.
+func respondError(c *gin.Context, statusCode int, err error) {
+	if isTransientError(err) {
+		c.Set(transientErrorKey, true)
+	}
+	c.JSON(statusCode, errorBody(err))
+}
+
+// errorCodes are the machine-readable codes of the errors clients are
+// expected to tell apart without parsing the detail.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{service.ErrPlateDenied, "plate_denied"},
+	{service.ErrPlateNotAllowed, "plate_not_allowed"},
+}
+
+// errorBody returns the response body for err, with its code if it has one.
+func errorBody(err error) gin.H {
+	body := gin.H{"detail": err.Error()}
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			body["code"] = errorCode.code
+			break
+		}
+	}
+	return body
+}
+
+func placeNumberParam(c *gin.Context) (int, bool) {
+	placeNumber, err := strconv.Atoi(c.Param("place_number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid place_number"})
+		return 0, false
+	}
+	return placeNumber, true
+}
+
+// @Summary      Список парковок
+// @Description  Возвращает парковки с количеством мест и их занятостью. Экземпляр сервиса обслуживает одну парковку
+// @Tags         lots
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   service.Lot
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/v1/lots [get]
+func (h *Handlers) ListLots(c *gin.Context) {
+	lots, err := h.service.GetLots(c.Request.Context())
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lots)
+}
+
+// @Summary      Получить парковку
+// @Description  Возвращает количество мест парковки и их занятость
+// @Tags         lots
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        lot_id  path      string  true  "Идентификатор парковки"
+// @Success      200     {object}  service.Lot
+// @Failure      401     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/lots/{lot_id} [get]
+func (h *Handlers) GetLot(c *gin.Context) {
+	lot, err := h.service.GetLot(c.Request.Context(), c.Param("lot_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lot)
+}
+
+// @Summary      Получить доступность мест парковки
+// @Description  Возвращает общее число мест, число занятых, свободных, зарезервированных и выведенных из эксплуатации мест, номера свободных мест и разбивку по зонам, уровням и типам мест
+// @Tags         lots
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        lot_id  path      string  true  "Идентификатор парковки"
+// @Success      200     {object}  service.Availability
+// @Failure      401     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/lots/{lot_id}/availability [get]
+func (h *Handlers) GetLotAvailability(c *gin.Context) {
+	availability, err := h.service.GetLotAvailability(c.Request.Context(), c.Param("lot_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, availability)
+}
+
+// @Summary      Получить карту мест парковки
+// @Description  Возвращает все места парковки со статусом (free, occupied, reserved, out_of_service), зоной, уровнем, типом, временем смены статуса и краткими данными занимающей место сессии. Версия карты передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304
+// @Tags         lots
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        lot_id         path      string  true   "Идентификатор парковки"
+// @Param        If-None-Match  header    string  false  "ETag, полученный ранее"
+// @Success      200            {array}   service.Place
+// @Success      304            "Карта не изменилась"
+// @Failure      401            {object}  map[string]string
+// @Failure      404            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /api/v1/lots/{lot_id}/places [get]
+func (h *Handlers) GetLotPlaces(c *gin.Context) {
+	placeMap, err := h.service.GetLotPlaceMap(c.Request.Context(), c.Param("lot_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	h.writePlaces(c, placeMap)
+}
+
+// writePlaces answers with the lot map, or with 304 if the client already
+// has it, in which case the map is not built.
+func (h *Handlers) writePlaces(c *gin.Context, placeMap *service.PlaceMap) {
+	etag := strconv.Quote(placeMap.ETag())
+	c.Header(ETagHeader, etag)
+	if ifNoneMatch(c, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	places, err := h.service.GetPlaces(c.Request.Context(), placeMap)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, places)
+}
+
+// @Summary      Список парковочных мест
+// @Description  Возвращает все места парковки по порядку номеров вместе с занимающими их сессиями
+// @Tags         spaces
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   service.Space
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/v1/spaces [get]
+func (h *Handlers) ListSpaces(c *gin.Context) {
+	spaces, err := h.service.GetSpaces(c.Request.Context())
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, spaces)
+}
+
+// @Summary      Получить парковочное место
+// @Description  Возвращает место и занимающую его сессию
+// @Tags         spaces
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        place_number  path      int  true  "Номер парковочного места"
+// @Success      200           {object}  service.Space
+// @Failure      400           {object}  map[string]string
+// @Failure      401           {object}  map[string]string
+// @Failure      404           {object}  map[string]string
+// @Failure      500           {object}  map[string]string
+// @Router       /api/v1/spaces/{place_number} [get]
+func (h *Handlers) GetSpace(c *gin.Context) {
+	placeNumber, ok := placeNumberParam(c)
+	if !ok {
+		return
+	}
+
+	space, err := h.service.GetSpace(c.Request.Context(), placeNumber)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, space)
+}
+
+// @Summary      Освободить парковочное место
+// @Description  Завершает сессию, занимающую место
+// @Tags         spaces
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        place_number     path      int     true   "Номер парковочного места"
+// @Success      204              "Место освобождено"
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/spaces/{place_number}/session [delete]
+func (h *Handlers) ReleaseSpace(c *gin.Context) {
+	placeNumber, ok := placeNumberParam(c)
+	if !ok {
+		return
+	}
+
+	_, err := h.service.FreeUpParkingSpace(c.Request.Context(), placeNumber)
+	if errors.Is(err, service.ErrSpaceAlreadyFree) {
+		c.JSON(http.StatusNotFound, gin.H{"detail": "no parking session on this space"})
+		return
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Список активных сессий
+// @Description  Возвращает активные парковочные сессии. С параметрами first_name и last_name — только сессии владельца, с overstayed=true — только превысившие максимальное время стоянки
+// @Tags         sessions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        first_name  query     string  false  "Имя владельца"
+// @Param        last_name   query     string  false  "Фамилия владельца"
+// @Param        overstayed  query     bool    false  "Только превышения времени стоянки"
+// @Success      200         {array}   models.ParkingSpaceLog
+// @Failure      400         {object}  map[string]string
+// @Failure      401         {object}  map[string]string
+// @Failure      500         {object}  map[string]string
+// @Router       /api/v1/sessions [get]
+func (h *Handlers) ListSessions(c *gin.Context) {
+	firstName := c.Query("first_name")
+	lastName := c.Query("last_name")
+	if (firstName == "") != (lastName == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "first_name and last_name must be given together"})
+		return
+	}
+	overstayed, err := strconv.ParseBool(c.DefaultQuery("overstayed", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "invalid overstayed"})
+		return
+	}
+	if overstayed && firstName != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": "overstayed cannot be combined with a name filter"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var sessions []models.ParkingSpaceLog
+	switch {
+	case overstayed:
+		sessions, err = h.service.GetOverstayedSessions(ctx)
+	case firstName != "":
+		sessions, err = h.service.GetParkingSpaceLogsByFirstNameAndLastName(ctx, firstName, lastName)
+	default:
+		sessions, err = h.service.GetOccupiedSpaces(ctx)
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, nonNil(sessions))
+}
+
+// @Summary      Начать парковочную сессию
+// @Description  Занимает свободное место согласно стратегии распределения. Автомобиль задаётся идентификатором vehicle_id или номером license_plate зарегистрированного автомобиля либо всеми данными автомобиля и водителя; переданные имя, фамилия и марка заменяют данные реестра. Адрес новой сессии передаётся в заголовке Location
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string                    false  "Ключ идемпотентности"
+// @Param        request          body      AddParkingSpaceLogSchema  true   "Данные автомобиля"
+// @Success      201              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      403              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      429              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/sessions [post]
+func (h *Handlers) CreateSession(c *gin.Context) {
+	var body AddParkingSpaceLogSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	log, err := h.service.AddParkingSpaceLog(c.Request.Context(), ClientID(c), body.parkRequest())
+	if errors.Is(err, service.ErrVehicleNotFound) {
+		// The vehicle is referenced by the body, not the path.
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+		return
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	c.Header("Location", V1Prefix+"/sessions/"+log.LogID)
+	setSessionETag(c, log)
+	c.JSON(http.StatusCreated, log)
+}
+
+// @Summary      Получить парковочную сессию
+// @Description  Возвращает парковочную сессию по её идентификатору. Версия сессии передаётся в заголовке ETag; при совпадении If-None-Match возвращается 304
+// @Tags         sessions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        log_id         path      string  true   "Идентификатор сессии"
+// @Param        If-None-Match  header    string  false  "ETag, полученный ранее"
+// @Success      200            {object}  models.ParkingSpaceLog
+// @Success      304            "Сессия не изменилась"
+// @Failure      401            {object}  map[string]string
+// @Failure      404            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /api/v1/sessions/{log_id} [get]
+func (h *Handlers) GetSession(c *gin.Context) {
+	log, err := h.service.GetParkingSession(c.Request.Context(), c.Param("log_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	setSessionETag(c, log)
+	if matchesIfNoneMatch(c, log) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, log)
+}
+
+// @Summary      Переместить автомобиль на другое место
+// @Description  Атомарно освобождает текущее место сессии и занимает указанное в place_number, сохраняя ту же сессию. Если номер места не указан, свободное место выбирается согласно стратегии распределения. С заголовком If-Match сессия изменяется, только если её ETag не изменился
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string                    false  "Ключ идемпотентности"
+// @Param        If-Match         header    string                    false  "ETag сессии или список ETag через запятую"
+// @Param        log_id           path      string                    true   "Идентификатор сессии"
+// @Param        request          body      MoveParkingSessionSchema  true   "Новое место"
+// @Success      200              {object}  models.ParkingSpaceLog
+// @Failure      400              {object}  map[string]string
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      412              {object}  map[string]string
+// @Failure      422              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/sessions/{log_id} [patch]
+func (h *Handlers) UpdateSession(c *gin.Context) {
+	var body MoveParkingSessionSchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"detail": err.Error()})
+		return
+	}
+
+	logID := c.Param("log_id")
+	expectedVersion, ok, err := h.sessionIfMatch(c, logID)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"detail": service.ErrPreconditionFailed.Error()})
+		return
+	}
+
+	log, err := h.service.MoveParkingSpaceLog(c.Request.Context(), logID, body.PlaceNumber, expectedVersion)
+	if errors.Is(err, service.ErrPlaceOutOfRange) {
+		// The session exists, it is the requested place that does not.
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"detail": err.Error()})
+		return
+	}
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+
+	setSessionETag(c, log)
+	c.JSON(http.StatusOK, log)
+}
+
+// @Summary      Завершить парковочную сессию
+// @Description  Завершает активную сессию и освобождает занятое ею место. Завершённая сессия остаётся доступной для чтения. С заголовком If-Match сессия завершается, только если её ETag не изменился
+// @Tags         sessions
+// @Security     ApiKeyAuth
+// @Param        Idempotency-Key  header    string  false  "Ключ идемпотентности"
+// @Param        If-Match         header    string  false  "ETag сессии или список ETag через запятую"
+// @Param        log_id           path      string  true   "Идентификатор сессии"
+// @Success      204              "Сессия завершена"
+// @Failure      401              {object}  map[string]string
+// @Failure      404              {object}  map[string]string
+// @Failure      409              {object}  map[string]string
+// @Failure      412              {object}  map[string]string
+// @Failure      500              {object}  map[string]string
+// @Router       /api/v1/sessions/{log_id} [delete]
+func (h *Handlers) DeleteSession(c *gin.Context) {
+	logID := c.Param("log_id")
+	expectedVersion, ok, err := h.sessionIfMatch(c, logID)
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"detail": service.ErrPreconditionFailed.Error()})
+		return
+	}
+
+	if _, err := h.service.EndParkingSession(c.Request.Context(), logID, expectedVersion); err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary      Получить чек парковочной сессии
+// @Description  Возвращает стоимость и длительность сессии. Для активной сессии чек предварительный и рассчитан на текущий момент
+// @Tags         sessions
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        log_id  path      string  true  "Идентификатор сессии"
+// @Success      200     {object}  models.ParkingReceipt
+// @Failure      401     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /api/v1/sessions/{log_id}/receipt [get]
+func (h *Handlers) GetSessionReceipt(c *gin.Context) {
+	receipt, err := h.service.GetParkingReceipt(c.Request.Context(), c.Param("log_id"))
+	if err != nil {
+		v1Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, receipt)
+}
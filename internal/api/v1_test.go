@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/service"
+)
+
+func TestErrorBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{name: "denied", err: service.ErrPlateDenied, wantCode: "plate_denied"},
+		{name: "not allowed", err: service.ErrPlateNotAllowed, wantCode: "plate_not_allowed"},
+		{name: "wrapped", err: fmt.Errorf("park: %w", service.ErrPlateDenied), wantCode: "plate_denied"},
+		{name: "without a code", err: service.ErrNoFreeSpaces},
+		{name: "other error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := errorBody(tt.err)
+			if body["detail"] != tt.err.Error() {
+				t.Errorf("detail = %v, want %q", body["detail"], tt.err.Error())
+			}
+			code, ok := body["code"]
+			if tt.wantCode == "" {
+				if ok {
+					t.Errorf("code = %v, want none", code)
+				}
+				return
+			}
+			if code != tt.wantCode {
+				t.Errorf("code = %v, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
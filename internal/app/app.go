@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+
+	"github.com/amend-parking-backend/internal/api"
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/database"
+	"github.com/amend-parking-backend/internal/events"
+	"github.com/amend-parking-backend/internal/health"
+	"github.com/amend-parking-backend/internal/logging"
+	"github.com/amend-parking-backend/internal/metrics"
+	"github.com/amend-parking-backend/internal/migrations"
+	"github.com/amend-parking-backend/internal/repository"
+	"github.com/amend-parking-backend/internal/service"
+)
+
+// App is one instance of the parking service with all of its dependencies.
+// Several instances can live in the same process, each with its own
+// configuration, database, metrics registry and rate limits. Tracing is the
+// exception: spans go to the global tracer provider that tracing.Init
+// installs once per process.
+type App struct {
+	cfg       *config.Config
+	logger    *slog.Logger
+	logLevel  *slog.LevelVar
+	db        *database.Database
+	svc       *service.Service
+	checker   *health.Checker
+	migrator  *migrations.Migrator
+	occupancy *metrics.Occupancy
+	handler   http.Handler
+
+	reloadMu sync.Mutex
+}
+
+// New wires the service for cfg. It does not wait for MongoDB; call Run to
+// connect and start the background tasks.
+func New(cfg *config.Config) (*App, error) {
+	logger, logLevel := logging.New(cfg)
+	m := metrics.New()
+
+	db, err := database.New(cfg, logger, m.MongoMonitor(), otelmongo.NewMonitor())
+	if err != nil {
+		return nil, fmt.Errorf("initialize database: %w", err)
+	}
+
+	repo := repository.NewRepository(db.DB, logger)
+	svc := service.NewService(cfg, repo, events.NewPublisher(cfg.WebhookURL, logger), m, logger)
+	occupancy := m.RegisterOccupancy(cfg.ParkingLotID, svc.GetOccupancy)
+
+	migrator := migrations.NewMigrator(db.DB, logger)
+	checker := health.NewChecker()
+	checker.AddReadinessCheck("mongodb", db.Ping)
+	checker.AddReadinessCheck("migrations", migrator.Check)
+	checker.AddReadinessCheck("config", func(context.Context) error {
+		return svc.Config().Validate()
+	})
+
+	a := &App{
+		cfg:       cfg,
+		logger:    logger,
+		logLevel:  logLevel,
+		db:        db,
+		svc:       svc,
+		checker:   checker,
+		migrator:  migrator,
+		occupancy: occupancy,
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	router.Use(api.CORS(cfg.CORS))
+	api.SetupRoutes(router, cfg, svc, db, checker, m, a, logger)
+	a.handler = router
+
+	return a, nil
+}
+
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.handler.ServeHTTP(w, r)
+}
+
+func (a *App) Logger() *slog.Logger {
+	return a.logger
+}
+
+// Run connects to MongoDB, applies pending migrations unless
+// MIGRATE_ON_STARTUP is off and runs the background monitors until ctx is
+// cancelled. Requests are served meanwhile and answer 503 until the database
+// is reachable.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.db.Connect(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("connect to database: %w", err)
+	}
+	go a.db.RunAvailabilityMonitor(ctx)
+
+	if a.cfg.MigrateOnStartup {
+		if _, err := a.migrator.Up(ctx); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("apply migrations: %w", err)
+		}
+	}
+	if err := a.svc.ReconcileCapacity(ctx); err != nil && ctx.Err() == nil {
+		a.logger.ErrorContext(ctx, "Failed to check sessions against the slot count", "error", err)
+	}
+
+	go a.occupancy.Run(ctx, a.cfg.MetricsRefreshPeriod)
+	go a.svc.RunPermitMonitor(ctx)
+	a.svc.RunOverstayMonitor(ctx)
+	return nil
+}
+
+// Reload re-reads the configuration and applies the settings that can change
+// at runtime: slot count, tariff, allocation strategy, overstay limits, the
+// per-client session limit and log level. Environment variables are fixed for
+// the life of the process, so in practice the changes come from the config
+// file; settings that the environment overrides are reported and left as
+// they are. Nothing is applied if the new configuration is invalid or would
+// strand occupied places.
+func (a *App) Reload(ctx context.Context) (config.ReloadResult, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	next, err := config.Load(a.cfg.File)
+	if err != nil {
+		return config.ReloadResult{}, err
+	}
+
+	result, err := a.svc.ApplyReload(ctx, next)
+	if err != nil {
+		return config.ReloadResult{}, err
+	}
+	if len(result.ShadowedByEnv) > 0 {
+		a.logger.WarnContext(ctx, "Config file settings are overridden by the environment", "settings", result.ShadowedByEnv)
+	}
+	if len(result.RestartRequired) > 0 {
+		a.logger.WarnContext(ctx, "Changed settings require a restart", "settings", result.RestartRequired)
+	}
+	if len(result.Changed) == 0 {
+		return result, nil
+	}
+
+	a.logLevel.Set(logging.ParseLevel(result.Config.LoggingLevel))
+	a.logger.InfoContext(ctx, "Configuration reloaded", "changed", result.Changed)
+	return result, nil
+}
+
+// SetShuttingDown makes the readiness probe fail so that traffic is drained
+// before the server stops.
+func (a *App) SetShuttingDown() {
+	a.checker.SetShuttingDown()
+}
+
+func (a *App) Close(ctx context.Context) error {
+	return a.db.Close(ctx)
+}
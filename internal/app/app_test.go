@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/config"
+)
+
+// newTestApp builds an App from the environment given, with MongoDB
+// unreachable so that requests stop at RequireDatabase.
+func newTestApp(t *testing.T, env map[string]string) *App {
+	t.Helper()
+	t.Setenv("MONGODB_URL", "mongodb://127.0.0.1:1")
+	t.Setenv("CONFIG_FILE", "")
+	for key, value := range env {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = a.Close(context.Background()) })
+	return a
+}
+
+func get(a *App, path, apiKey string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if apiKey != "" {
+		request.Header.Set("X-API-Key", apiKey)
+	}
+	a.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestAppsAreIsolated(t *testing.T) {
+	first := newTestApp(t, map[string]string{
+		"PARKING_SERVICE_API_KEY":      "first-key",
+		"ADMIN_API_KEY":                "first-admin-key",
+		"PARKING_LOT_ID":               "first",
+		"RATE_LIMIT_CLIENT_READ_BURST": "1",
+	})
+	second := newTestApp(t, map[string]string{
+		"PARKING_SERVICE_API_KEY":      "second-key",
+		"ADMIN_API_KEY":                "second-admin-key",
+		"PARKING_LOT_ID":               "second",
+		"RATE_LIMIT_CLIENT_READ_BURST": "1",
+	})
+
+	tests := []struct {
+		name       string
+		app        *App
+		path       string
+		apiKey     string
+		wantStatus int
+	}{
+		// Without MongoDB an authenticated request ends with 503.
+		{name: "first key on the first app", app: first, path: "/api/v1/lots", apiKey: "first-key", wantStatus: http.StatusServiceUnavailable},
+		{name: "first key on the second app", app: second, path: "/api/v1/lots", apiKey: "first-key", wantStatus: http.StatusUnauthorized},
+		{name: "first app rate limited", app: first, path: "/api/v1/lots", apiKey: "first-key", wantStatus: http.StatusTooManyRequests},
+		{name: "second app has its own limiter", app: second, path: "/api/v1/lots", apiKey: "second-key", wantStatus: http.StatusServiceUnavailable},
+		{name: "first admin key on the second app", app: second, path: "/admin/capacity", apiKey: "first-admin-key", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := get(tt.app, tt.path, tt.apiKey).Code; got != tt.wantStatus {
+				t.Errorf("status = %d, want %d", got, tt.wantStatus)
+			}
+		})
+	}
+
+	t.Run("separate metrics", func(t *testing.T) {
+		unauthorized := `route="/api/v1/lots",status="401"`
+		if body := get(first, "/metrics", "").Body.String(); strings.Contains(body, unauthorized) {
+			t.Errorf("first app reports the rejected requests of the second one")
+		}
+		if body := get(second, "/metrics", "").Body.String(); !strings.Contains(body, unauthorized) {
+			t.Errorf("second app does not report its rejected request")
+		}
+	})
+
+	t.Run("separate configuration", func(t *testing.T) {
+		if first.svc.Config().ParkingLotID != "first" || second.svc.Config().ParkingLotID != "second" {
+			t.Errorf("lot IDs = %q, %q, want first, second", first.svc.Config().ParkingLotID, second.svc.Config().ParkingLotID)
+		}
+	})
+}
+
+func TestFailedAuthenticationIsRateLimited(t *testing.T) {
+	a := newTestApp(t, map[string]string{
+		"PARKING_SERVICE_API_KEY": "key",
+		"ADMIN_API_KEY":           "admin-key",
+		"RATE_LIMIT_READ_BURST":   "2",
+	})
+
+	// The per IP budget is shared by the API and the admin routes and is
+	// spent before the key is checked.
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		path := "/api/v1/lots"
+		if i == 1 {
+			path = "/admin/capacity"
+		}
+		if got := get(a, path, "wrong-key").Code; got != want {
+			t.Errorf("request %d: status = %d, want %d", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultSpaceType is the type of the places not covered by
+// PARKING_SPACE_TYPES.
+const DefaultSpaceType = "standard"
+
+// PlaceLabel assigns Label, e.g. a zone name, to the places
+// FromPlace..ToPlace.
+type PlaceLabel struct {
+	FromPlace int
+	ToPlace   int
+	Label     string
+}
+
+// SpaceCatalogue describes where the places of the lot are and what they are
+// for. The first label whose range contains a place applies to it.
+type SpaceCatalogue struct {
+	Zones  []PlaceLabel
+	Levels []PlaceLabel
+	Types  []PlaceLabel
+}
+
+// Zone returns the zone of placeNumber, or "" if it has none.
+func (c SpaceCatalogue) Zone(placeNumber int) string {
+	return labelOf(c.Zones, placeNumber)
+}
+
+// Level returns the level of placeNumber, or "" if it has none.
+func (c SpaceCatalogue) Level(placeNumber int) string {
+	return labelOf(c.Levels, placeNumber)
+}
+
+func (c SpaceCatalogue) Type(placeNumber int) string {
+	if spaceType := labelOf(c.Types, placeNumber); spaceType != "" {
+		return spaceType
+	}
+	return DefaultSpaceType
+}
+
+// HasZone reports whether some places belong to zone.
+func (c SpaceCatalogue) HasZone(zone string) bool {
+	for _, label := range c.Zones {
+		if label.Label == zone {
+			return true
+		}
+	}
+	return false
+}
+
+func labelOf(labels []PlaceLabel, placeNumber int) string {
+	for _, label := range labels {
+		if placeNumber >= label.FromPlace && placeNumber <= label.ToPlace {
+			return label.Label
+		}
+	}
+	return ""
+}
+
+func parsePlaceLabel(value string) (PlaceLabel, error) {
+	places, label, found := strings.Cut(value, "=")
+	label = strings.TrimSpace(label)
+	if !found || label == "" {
+		return PlaceLabel{}, fmt.Errorf("expected <places>=<label>")
+	}
+
+	from, to, err := parsePlaceRange(places)
+	if err != nil {
+		return PlaceLabel{}, err
+	}
+	return PlaceLabel{FromPlace: from, ToPlace: to, Label: label}, nil
+}
+
+// parsePlaceRange parses a place number such as "10" or a range such as
+// "1-4".
+func parsePlaceRange(places string) (from, to int, err error) {
+	fromStr, toStr, isRange := strings.Cut(places, "-")
+	if !isRange {
+		toStr = fromStr
+	}
+	from, err = strconv.Atoi(fromStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err = strconv.Atoi(toStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if from < 1 || to < from {
+		return 0, 0, fmt.Errorf("invalid place range %s", places)
+	}
+	return from, to, nil
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,58 +15,289 @@ type Config struct {
 	LoggingDateFormat    string
 	LoggingLevel         string
 	MongoDBURL           string
+	MongoDB              MongoDBConfig
 	AppTitle             string
 	DBName               string
 	ParkingServiceAPIKey string
+	AdminAPIKey          string
+	ParkingLotID         string
 	ParkingSlotsCount    int
+	ParkingHourlyRate    int
+	AllocationStrategy   string
+	Spaces               SpaceCatalogue
 	ServerPort           string
+	IdempotencyKeyTTL    time.Duration
+	OverstayMaxStay      time.Duration
+	OverstayRules        []OverstayRule
+	OverstayCheckPeriod  time.Duration
+	PermitZones          []string
+	PermitExpiryNotice   time.Duration
+	PermitCheckPeriod    time.Duration
+	AuditDenialRetention time.Duration
+	WebhookURL           string
+	TracingExporter      string
+	TracingServiceName   string
+	TracingSampleRatio   float64
+	ShutdownDrainDelay   time.Duration
+	MigrateOnStartup     bool
+	MetricsRefreshPeriod time.Duration
+	RateLimit            RateLimitConfig
+	TrustedProxies       []string
+	CORS                 CORSConfig
+	TLS                  TLSConfig
+	MaxActiveSessions    int
+	LegacyAPISunset      time.Time
+
+	// File is the config file the settings were read from, if any.
+	File string
+
+	settings []Setting
+	// shadowed holds the settings whose config file value is overridden by
+	// a different environment value.
+	shadowed map[string]bool
+	// loadedSlotsCount is the PARKING_SLOTS_COUNT setting as loaded, kept
+	// while a runtime slot count overrides it.
+	loadedSlotsCount *Setting
+}
+
+const (
+	AllocationRandom = "random"
+	AllocationLowest = "lowest"
+)
+
+// MongoDBConfig tunes the MongoDB client and the behaviour while the
+// database is unreachable.
+type MongoDBConfig struct {
+	ConnectMaxAttempts     int
+	ConnectRetryInitial    time.Duration
+	ConnectRetryMax        time.Duration
+	HealthCheckPeriod      time.Duration
+	MaxPoolSize            int
+	MinPoolSize            int
+	MaxConnIdleTime        time.Duration
+	ConnectTimeout         time.Duration
+	ServerSelectionTimeout time.Duration
+	SocketTimeout          time.Duration
+	ReadConcern            string
+	WriteConcern           string
+}
+
+// RateLimitConfig sets the token bucket budgets that every client IP and,
+// separately, every API key get, each split into reads and mutations. A zero
+// rate disables the limit. MaxClients bounds the number of buckets kept in
+// memory.
+type RateLimitConfig struct {
+	ReadsPerMinute           int
+	ReadBurst                int
+	MutationsPerMinute       int
+	MutationBurst            int
+	ClientReadsPerMinute     int
+	ClientReadBurst          int
+	ClientMutationsPerMinute int
+	ClientMutationBurst      int
+	MaxClients               int
+}
+
+// CORSConfig is the cross-origin policy for browser clients. AllowOrigins
+// holds exact origins or patterns such as https://*.example.com, where *
+// stands for one or more host labels or a port. With no origins, cross-origin
+// requests are not allowed.
+type CORSConfig struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// TLSConfig enables HTTPS when a certificate is set, and mutual TLS when
+// client certificates are verified against ClientCAFile. ClientIdentities
+// maps the common names of client certificates to the identities they
+// authenticate as; certificates with other common names do not authenticate.
+// AdminIdentities lists the identities that may also use the admin endpoints.
+// HealthcheckCertFile and HealthcheckKeyFile are the client certificate the
+// healthcheck command presents when client certificates are required.
+type TLSConfig struct {
+	CertFile            string
+	KeyFile             string
+	ReloadPeriod        time.Duration
+	ClientCAFile        string
+	ClientAuth          string
+	ClientIdentities    map[string]string
+	AdminIdentities     []string
+	HealthcheckCertFile string
+	HealthcheckKeyFile  string
+}
+
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != ""
 }
 
-var Settings *Config
+// OverstayRule overrides the maximum stay for the places FromPlace..ToPlace,
+// e.g. for EV chargers that must not be blocked for long.
+type OverstayRule struct {
+	FromPlace int
+	ToPlace   int
+	MaxStay   time.Duration
+}
 
-func LoadConfig() {
+// Load reads the configuration and validates it. Each setting is taken from
+// the environment (including the .env file), then from the config file and
+// finally from its default. The config file is configFile or, if that is
+// empty, CONFIG_FILE. Every invalid setting is reported in one
+// *ValidationError, together with the Config as loaded so that callers can
+// show it.
+func Load(configFile string) (*Config, error) {
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
-	Settings = &Config{
-		LoggingFormat:        getEnv("LOGGING_FORMAT", "%(asctime)s,%(msecs)d %(levelname)-8s [%(pathname)s:%(lineno)d in function %(funcName)s] %(message)s"),
-		LoggingDateFormat:    getEnv("LOGGING_DATE_FORMAT", "2006-01-02 15:04:05"),
-		LoggingLevel:         getEnv("LOGGING_LEVEL", "INFO"),
-		MongoDBURL:           getEnv("MONGODB_URL", "mongodb://mongodb:27017"),
-		AppTitle:             getEnv("APP_TITLE", "Parking Service"),
-		DBName:               getEnv("DB_NAME", "ParkingService"),
-		ParkingServiceAPIKey: getEnvRequired("PARKING_SERVICE_API_KEY"),
-		ParkingSlotsCount:    getEnvAsInt("PARKING_SLOTS_COUNT", 52),
-		ServerPort:           getEnv("SERVER_PORT", "8000"),
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
+	}
+	l, err := newLoader(configFile)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	cfg := &Config{
+		LoggingFormat:     l.string("LOGGING_FORMAT", "text"),
+		LoggingDateFormat: l.string("LOGGING_DATE_FORMAT", "2006-01-02 15:04:05"),
+		LoggingLevel:      l.string("LOGGING_LEVEL", "INFO"),
+		MongoDBURL:        l.string("MONGODB_URL", "mongodb://mongodb:27017"),
+		MongoDB: MongoDBConfig{
+			ConnectMaxAttempts:     l.int("MONGODB_CONNECT_MAX_ATTEMPTS", 0),
+			ConnectRetryInitial:    l.duration("MONGODB_CONNECT_RETRY_INITIAL", time.Second),
+			ConnectRetryMax:        l.duration("MONGODB_CONNECT_RETRY_MAX", 30*time.Second),
+			HealthCheckPeriod:      l.duration("MONGODB_HEALTH_CHECK_PERIOD", 5*time.Second),
+			MaxPoolSize:            l.int("MONGODB_MAX_POOL_SIZE", 100),
+			MinPoolSize:            l.int("MONGODB_MIN_POOL_SIZE", 0),
+			MaxConnIdleTime:        l.duration("MONGODB_MAX_CONN_IDLE_TIME", 0),
+			ConnectTimeout:         l.duration("MONGODB_CONNECT_TIMEOUT", 10*time.Second),
+			ServerSelectionTimeout: l.duration("MONGODB_SERVER_SELECTION_TIMEOUT", 5*time.Second),
+			SocketTimeout:          l.duration("MONGODB_SOCKET_TIMEOUT", 0),
+			ReadConcern:            l.string("MONGODB_READ_CONCERN", ""),
+			WriteConcern:           l.string("MONGODB_WRITE_CONCERN", ""),
+		},
+		AppTitle:             l.string("APP_TITLE", "Parking Service"),
+		DBName:               l.string("DB_NAME", "ParkingService"),
+		ParkingServiceAPIKey: l.string("PARKING_SERVICE_API_KEY", ""),
+		AdminAPIKey:          l.string("ADMIN_API_KEY", ""),
+		ParkingLotID:         l.string("PARKING_LOT_ID", "main"),
+		ParkingSlotsCount:    l.int("PARKING_SLOTS_COUNT", 52),
+		ParkingHourlyRate:    l.int("PARKING_HOURLY_RATE", 0),
+		AllocationStrategy:   l.string("PARKING_ALLOCATION_STRATEGY", AllocationRandom),
+		ServerPort:           l.string("SERVER_PORT", "8000"),
+		IdempotencyKeyTTL:    l.duration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		OverstayMaxStay:      l.duration("OVERSTAY_MAX_STAY", 72*time.Hour),
+		OverstayRules:        l.overstayRules("OVERSTAY_RULES"),
+		OverstayCheckPeriod:  l.duration("OVERSTAY_CHECK_PERIOD", time.Minute),
+		PermitZones:          l.list("PERMIT_ZONES", ""),
+		PermitExpiryNotice:   l.duration("PERMIT_EXPIRY_NOTICE", 7*24*time.Hour),
+		PermitCheckPeriod:    l.duration("PERMIT_CHECK_PERIOD", time.Hour),
+		AuditDenialRetention: l.duration("AUDIT_DENIAL_RETENTION", 30*24*time.Hour),
+		WebhookURL:           l.string("WEBHOOK_URL", ""),
+		TracingExporter:      l.string("OTEL_TRACES_EXPORTER", "none"),
+		TracingServiceName:   l.string("OTEL_SERVICE_NAME", "parking-service"),
+		TracingSampleRatio:   l.float("OTEL_TRACES_SAMPLE_RATIO", 1),
+		ShutdownDrainDelay:   l.duration("SHUTDOWN_DRAIN_DELAY", 5*time.Second),
+		MigrateOnStartup:     l.bool("MIGRATE_ON_STARTUP", true),
+		MetricsRefreshPeriod: l.duration("METRICS_REFRESH_PERIOD", 15*time.Second),
+		Spaces: SpaceCatalogue{
+			Zones:  l.placeLabels("PARKING_ZONES"),
+			Levels: l.placeLabels("PARKING_LEVELS"),
+			Types:  l.placeLabels("PARKING_SPACE_TYPES"),
+		},
+		RateLimit: RateLimitConfig{
+			ReadsPerMinute:           l.int("RATE_LIMIT_READS_PER_MINUTE", 600),
+			ReadBurst:                l.int("RATE_LIMIT_READ_BURST", 100),
+			MutationsPerMinute:       l.int("RATE_LIMIT_MUTATIONS_PER_MINUTE", 60),
+			MutationBurst:            l.int("RATE_LIMIT_MUTATION_BURST", 10),
+			ClientReadsPerMinute:     l.int("RATE_LIMIT_CLIENT_READS_PER_MINUTE", 6000),
+			ClientReadBurst:          l.int("RATE_LIMIT_CLIENT_READ_BURST", 1000),
+			ClientMutationsPerMinute: l.int("RATE_LIMIT_CLIENT_MUTATIONS_PER_MINUTE", 600),
+			ClientMutationBurst:      l.int("RATE_LIMIT_CLIENT_MUTATION_BURST", 100),
+			MaxClients:               l.int("RATE_LIMIT_MAX_CLIENTS", 100000),
+		},
+		TrustedProxies:    l.list("TRUSTED_PROXIES", ""),
+		MaxActiveSessions: l.int("MAX_ACTIVE_SESSIONS_PER_CLIENT", 0),
+		LegacyAPISunset:   l.date("LEGACY_API_SUNSET", "2027-04-30"),
+		TLS: TLSConfig{
+			CertFile:            l.string("TLS_CERT_FILE", ""),
+			KeyFile:             l.string("TLS_KEY_FILE", ""),
+			ReloadPeriod:        l.duration("TLS_RELOAD_PERIOD", time.Minute),
+			ClientCAFile:        l.string("TLS_CLIENT_CA_FILE", ""),
+			ClientAuth:          l.string("TLS_CLIENT_AUTH", ClientAuthOptional),
+			ClientIdentities:    l.identities("TLS_CLIENT_IDENTITIES"),
+			AdminIdentities:     l.list("TLS_ADMIN_IDENTITIES", ""),
+			HealthcheckCertFile: l.string("TLS_HEALTHCHECK_CERT_FILE", ""),
+			HealthcheckKeyFile:  l.string("TLS_HEALTHCHECK_KEY_FILE", ""),
+		},
+		CORS: CORSConfig{
+			AllowOrigins:     l.list("CORS_ALLOW_ORIGINS", ""),
+			AllowMethods:     l.list("CORS_ALLOW_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS"),
+			AllowHeaders:     l.list("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization,X-API-Key,Idempotency-Key,If-Match,If-None-Match,X-Request-ID,traceparent,tracestate"),
+			AllowCredentials: l.bool("CORS_ALLOW_CREDENTIALS", false),
+			MaxAge:           l.duration("CORS_MAX_AGE", 12*time.Hour),
+		},
 	}
-	return defaultValue
-}
+	cfg.File = configFile
+	cfg.settings = l.settings
+	cfg.shadowed = l.shadowed
 
-func getEnvRequired(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		log.Fatalf("Required environment variable %s is not set", key)
+	problems := append(l.errs, l.unknownFileKeys()...)
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Errors: problems}
 	}
-	return value
+	return cfg, nil
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+func parseOverstayRule(value string) (OverstayRule, error) {
+	places, maxStayStr, found := strings.Cut(value, "=")
+	if !found {
+		return OverstayRule{}, fmt.Errorf("expected <places>=<duration>")
+	}
+
+	maxStay, err := time.ParseDuration(maxStayStr)
+	if err != nil {
+		return OverstayRule{}, err
 	}
-	value, err := strconv.Atoi(valueStr)
+
+	from, to, err := parsePlaceRange(places)
 	if err != nil {
-		log.Printf("Warning: Invalid integer value for %s, using default: %d", key, defaultValue)
-		return defaultValue
+		return OverstayRule{}, err
+	}
+
+	return OverstayRule{FromPlace: from, ToPlace: to, MaxStay: maxStay}, nil
+}
+
+// MaxStayFor returns the maximum stay for placeNumber. The first matching rule
+// wins; zero means the stay is unlimited.
+func (c *Config) MaxStayFor(placeNumber int) time.Duration {
+	for _, rule := range c.OverstayRules {
+		if placeNumber >= rule.FromPlace && placeNumber <= rule.ToPlace {
+			return rule.MaxStay
+		}
+	}
+	return c.OverstayMaxStay
+}
+
+// IsPermitZone reports whether placeNumber lies in one of the zones reserved
+// for permit holders.
+func (c *Config) IsPermitZone(placeNumber int) bool {
+	zone := c.Spaces.Zone(placeNumber)
+	for _, permitZone := range c.PermitZones {
+		if zone != "" && zone == permitZone {
+			return true
+		}
 	}
-	return value
+	return false
 }
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	SourceEnv     = "env"
+	SourceDefault = "default"
+	SourceRuntime = "runtime"
+)
+
+// secretKeys are settings whose values are never shown.
+var secretKeys = map[string]bool{
+	"PARKING_SERVICE_API_KEY": true,
+	"ADMIN_API_KEY":           true,
+}
+
+// urlKeys are settings holding URLs that may contain credentials.
+var urlKeys = map[string]bool{
+	"MONGODB_URL": true,
+	"WEBHOOK_URL": true,
+}
+
+// Setting is the effective value of one setting and where it came from: the
+// environment, the config file or the default.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// Effective lists the settings in the order they are loaded, with secrets
+// masked.
+func (c *Config) Effective() []Setting {
+	settings := make([]Setting, len(c.settings))
+	for i, setting := range c.settings {
+		settings[i] = setting
+		settings[i].Value = maskValue(setting.Key, setting.Value)
+	}
+	return settings
+}
+
+func maskValue(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if secretKeys[key] {
+		return "********"
+	}
+	if urlKeys[key] {
+		if u, err := url.Parse(value); err == nil {
+			return u.Redacted()
+		}
+	}
+	return value
+}
+
+// loader looks settings up in the environment and then in the config file,
+// collecting every problem instead of stopping at the first one.
+type loader struct {
+	fileName string
+	file     map[string]string
+	used     map[string]bool
+	settings []Setting
+	shadowed map[string]bool
+	errs     []*FieldError
+}
+
+func newLoader(fileName string) (*loader, error) {
+	l := &loader{fileName: fileName, used: make(map[string]bool), shadowed: make(map[string]bool)}
+	if fileName == "" {
+		return l, nil
+	}
+
+	file, err := readConfigFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", fileName, err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// readConfigFile reads a YAML or TOML file and flattens it into settings
+// named like the environment variables, so that
+//
+//	mongodb:
+//	  max_pool_size: 50
+//
+// sets MONGODB_MAX_POOL_SIZE. Lists are joined with commas.
+func readConfigFile(fileName string) (map[string]string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]any
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q, use .yaml or .toml", filepath.Ext(fileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := make(map[string]string)
+	flatten("", values, file)
+	return file, nil
+}
+
+func flatten(prefix string, values map[string]any, file map[string]string) {
+	for name, value := range values {
+		key := strings.ToUpper(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(key, value, file)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			file[key] = strings.Join(items, ",")
+		case nil:
+			file[key] = ""
+		default:
+			file[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+func (l *loader) lookup(key string) (value, source string, found bool) {
+	l.used[key] = true
+	if value := os.Getenv(key); value != "" {
+		if fileValue := l.file[key]; fileValue != "" && fileValue != value {
+			l.shadowed[key] = true
+		}
+		return value, SourceEnv, true
+	}
+	if value := l.file[key]; value != "" {
+		return value, l.fileName, true
+	}
+	return "", SourceDefault, false
+}
+
+func (l *loader) fail(key, format string, args ...any) {
+	l.errs = append(l.errs, fieldError(key, format, args...))
+}
+
+// unknownFileKeys reports config file settings that nothing reads, which are
+// most likely typos.
+func (l *loader) unknownFileKeys() []*FieldError {
+	var keys []string
+	for key := range l.file {
+		if !l.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	errs := make([]*FieldError, len(keys))
+	for i, key := range keys {
+		errs[i] = fieldError(key, "is not a known setting (in %s)", l.fileName)
+	}
+	return errs
+}
+
+// value looks key up and parses it. An unparsable value is reported and the
+// default is used in its place so that loading can go on.
+func value[T any](l *loader, key string, defaultValue T, kind string, parse func(string) (T, error)) T {
+	raw, source, found := l.lookup(key)
+	if !found {
+		l.settings = append(l.settings, Setting{Key: key, Value: fmt.Sprint(defaultValue), Source: SourceDefault})
+		return defaultValue
+	}
+
+	l.settings = append(l.settings, Setting{Key: key, Value: raw, Source: source})
+	parsed, err := parse(strings.TrimSpace(raw))
+	if err != nil {
+		l.fail(key, "must be %s, got %q", kind, raw)
+		return defaultValue
+	}
+	return parsed
+}
+
+func (l *loader) string(key, defaultValue string) string {
+	return value(l, key, defaultValue, "a string", func(s string) (string, error) { return s, nil })
+}
+
+// list reads a comma-separated list, dropping empty items.
+func (l *loader) list(key, defaultValue string) []string {
+	var items []string
+	for _, item := range strings.Split(l.string(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	return value(l, key, defaultValue, "an integer", strconv.Atoi)
+}
+
+func (l *loader) float(key string, defaultValue float64) float64 {
+	return value(l, key, defaultValue, "a number", func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	return value(l, key, defaultValue, "true or false", strconv.ParseBool)
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	return value(l, key, defaultValue, "a duration such as 30s or 5m", time.ParseDuration)
+}
+
+// date reads a calendar date such as 2027-04-30, in UTC.
+func (l *loader) date(key, defaultValue string) time.Time {
+	parse := func(s string) (time.Time, error) { return time.Parse(time.DateOnly, s) }
+	fallback, _ := parse(defaultValue)
+	return value(l, key, fallback, "a date such as 2027-04-30", parse)
+}
+
+// overstayRules parses rules in the form "1-4=4h,10=8h".
+func (l *loader) overstayRules(key string) []OverstayRule {
+	return value(l, key, nil, "a list of rules such as 1-4=4h,10=8h", func(s string) ([]OverstayRule, error) {
+		var rules []OverstayRule
+		for _, item := range strings.Split(s, ",") {
+			rule, err := parseOverstayRule(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		return rules, nil
+	})
+}
+
+// placeLabels parses labels in the form "1-20=A,21-40=B".
+func (l *loader) placeLabels(key string) []PlaceLabel {
+	return value(l, key, nil, "a list of labels such as 1-20=A,21-40=B", func(s string) ([]PlaceLabel, error) {
+		var labels []PlaceLabel
+		for _, item := range strings.Split(s, ",") {
+			label, err := parsePlaceLabel(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			labels = append(labels, label)
+		}
+		return labels, nil
+	})
+}
+
+// identities parses "<common name>:<identity>" pairs such as
+// "gate-north-01:gate-north,gate-south-01:gate-south".
+func (l *loader) identities(key string) map[string]string {
+	return value(l, key, nil, "a list such as gate-01:gate-north", func(s string) (map[string]string, error) {
+		identities := make(map[string]string)
+		for _, item := range strings.Split(s, ",") {
+			commonName, identity, found := strings.Cut(strings.TrimSpace(item), ":")
+			if !found || commonName == "" || identity == "" {
+				return nil, fmt.Errorf("expected <common name>:<identity>")
+			}
+			identities[commonName] = identity
+		}
+		return identities, nil
+	})
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoaderPrecedence(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          string
+		file         string
+		wantValue    int
+		wantSource   string
+		wantShadowed bool
+	}{
+		{name: "default", wantValue: 52, wantSource: SourceDefault},
+		{name: "file", file: "60", wantValue: 60, wantSource: testConfigFile},
+		{name: "environment", env: "70", wantValue: 70, wantSource: SourceEnv},
+		{name: "environment over the file", env: "70", file: "60", wantValue: 70, wantSource: SourceEnv, wantShadowed: true},
+		{name: "environment equal to the file", env: "60", file: "60", wantValue: 60, wantSource: SourceEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PARKING_SLOTS_COUNT", tt.env)
+			t.Chdir(t.TempDir())
+			content := ""
+			if tt.file != "" {
+				content = "parking_slots_count: " + tt.file + "\n"
+			}
+			if err := os.WriteFile(filepath.Join(".", testConfigFile), []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := newLoader(testConfigFile)
+			if err != nil {
+				t.Fatalf("newLoader() error = %v", err)
+			}
+			if got := l.int("PARKING_SLOTS_COUNT", 52); got != tt.wantValue {
+				t.Errorf("value = %d, want %d", got, tt.wantValue)
+			}
+			if source := l.settings[0].Source; source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			if shadowed := l.shadowed["PARKING_SLOTS_COUNT"]; shadowed != tt.wantShadowed {
+				t.Errorf("shadowed = %v, want %v", shadowed, tt.wantShadowed)
+			}
+		})
+	}
+}
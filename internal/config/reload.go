@@ -0,0 +1,109 @@
+package config
+
+import "strconv"
+
+// reloadableKeys are the settings that take effect without a restart.
+// Everything else, e.g. connection settings, is only read at startup.
+var reloadableKeys = map[string]bool{
+	"PARKING_SLOTS_COUNT":            true,
+	"PARKING_HOURLY_RATE":            true,
+	"PARKING_ALLOCATION_STRATEGY":    true,
+	"PARKING_ZONES":                  true,
+	"PARKING_LEVELS":                 true,
+	"PARKING_SPACE_TYPES":            true,
+	"OVERSTAY_MAX_STAY":              true,
+	"OVERSTAY_RULES":                 true,
+	"PERMIT_ZONES":                   true,
+	"PERMIT_EXPIRY_NOTICE":           true,
+	"LOGGING_LEVEL":                  true,
+	"MAX_ACTIVE_SESSIONS_PER_CLIENT": true,
+}
+
+// ReloadResult is the outcome of applying a reloaded configuration.
+type ReloadResult struct {
+	// Config is the current configuration with the reloadable settings
+	// taken from the reloaded one.
+	Config *Config
+	// Changed lists the reloadable settings whose value changed.
+	Changed []string
+	// RestartRequired lists the changed settings that only take effect
+	// after a restart.
+	RestartRequired []string
+	// ShadowedByEnv lists the reloadable settings whose config file value
+	// is ignored because the environment sets them. The environment does
+	// not change while the process runs, so a reload cannot change them.
+	ShadowedByEnv []string
+}
+
+// ApplyReload returns a copy of c with the reloadable settings of next. c is
+// not modified, so it can be swapped for the result atomically. A slot count
+// set at runtime is kept unless the loaded PARKING_SLOTS_COUNT changed since.
+func (c *Config) ApplyReload(next *Config) ReloadResult {
+	merged := *c
+	merged.ParkingSlotsCount = next.ParkingSlotsCount
+	merged.ParkingHourlyRate = next.ParkingHourlyRate
+	merged.AllocationStrategy = next.AllocationStrategy
+	merged.Spaces = next.Spaces
+	merged.OverstayMaxStay = next.OverstayMaxStay
+	merged.OverstayRules = next.OverstayRules
+	merged.PermitZones = next.PermitZones
+	merged.PermitExpiryNotice = next.PermitExpiryNotice
+	merged.LoggingLevel = next.LoggingLevel
+	merged.MaxActiveSessions = next.MaxActiveSessions
+
+	current := make(map[string]Setting, len(c.settings))
+	for _, setting := range c.settings {
+		current[setting.Key] = setting
+	}
+
+	result := ReloadResult{Config: &merged}
+	merged.settings = make([]Setting, 0, len(c.settings))
+	for _, setting := range next.settings {
+		old := current[setting.Key]
+		if !reloadableKeys[setting.Key] {
+			if old.Value != setting.Value {
+				result.RestartRequired = append(result.RestartRequired, setting.Key)
+			}
+			merged.settings = append(merged.settings, old)
+			continue
+		}
+
+		if next.shadowed[setting.Key] {
+			result.ShadowedByEnv = append(result.ShadowedByEnv, setting.Key)
+		}
+		if setting.Key == "PARKING_SLOTS_COUNT" && c.loadedSlotsCount != nil {
+			if setting.Value == c.loadedSlotsCount.Value {
+				merged.ParkingSlotsCount = c.ParkingSlotsCount
+				merged.settings = append(merged.settings, old)
+				continue
+			}
+			merged.loadedSlotsCount = nil
+		}
+
+		if old.Value != setting.Value {
+			result.Changed = append(result.Changed, setting.Key)
+		}
+		merged.settings = append(merged.settings, setting)
+	}
+	return result
+}
+
+// WithSlotsCount returns a copy of c with a slot count set at runtime. It is
+// recorded as an effective setting, and the loaded setting is remembered so
+// that a reload only replaces the runtime value once the loaded one changes.
+func (c *Config) WithSlotsCount(slotsCount int) *Config {
+	updated := *c
+	updated.ParkingSlotsCount = slotsCount
+	updated.settings = make([]Setting, len(c.settings))
+	for i, setting := range c.settings {
+		if setting.Key == "PARKING_SLOTS_COUNT" {
+			if updated.loadedSlotsCount == nil {
+				loaded := setting
+				updated.loadedSlotsCount = &loaded
+			}
+			setting = Setting{Key: setting.Key, Value: strconv.Itoa(slotsCount), Source: SourceRuntime}
+		}
+		updated.settings[i] = setting
+	}
+	return &updated
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// testConfigFile is the source of settings read from a config file, which is
+// the name of the file.
+const testConfigFile = "config.yaml"
+
+func slotsConfig(slotsCount int, source string) *Config {
+	return &Config{
+		ParkingSlotsCount: slotsCount,
+		settings: []Setting{
+			{Key: "PARKING_SLOTS_COUNT", Value: strconv.Itoa(slotsCount), Source: source},
+		},
+	}
+}
+
+func TestApplyReloadSlotsCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     *Config
+		next        *Config
+		wantSlots   int
+		wantChanged bool
+		wantSource  string
+	}{
+		{
+			name:        "loaded value changed",
+			current:     slotsConfig(40, testConfigFile),
+			next:        slotsConfig(50, testConfigFile),
+			wantSlots:   50,
+			wantChanged: true,
+			wantSource:  testConfigFile,
+		},
+		{
+			name:       "loaded value unchanged",
+			current:    slotsConfig(40, testConfigFile),
+			next:       slotsConfig(40, testConfigFile),
+			wantSlots:  40,
+			wantSource: testConfigFile,
+		},
+		{
+			name:       "runtime value kept while the file is unchanged",
+			current:    slotsConfig(40, testConfigFile).WithSlotsCount(30),
+			next:       slotsConfig(40, testConfigFile),
+			wantSlots:  30,
+			wantSource: SourceRuntime,
+		},
+		{
+			name:       "runtime value kept after two runtime changes",
+			current:    slotsConfig(40, testConfigFile).WithSlotsCount(30).WithSlotsCount(20),
+			next:       slotsConfig(40, testConfigFile),
+			wantSlots:  20,
+			wantSource: SourceRuntime,
+		},
+		{
+			name:        "runtime value replaced once the file changes",
+			current:     slotsConfig(40, testConfigFile).WithSlotsCount(30),
+			next:        slotsConfig(45, testConfigFile),
+			wantSlots:   45,
+			wantChanged: true,
+			wantSource:  testConfigFile,
+		},
+		{
+			name:       "file changed to the runtime value",
+			current:    slotsConfig(40, testConfigFile).WithSlotsCount(30),
+			next:       slotsConfig(30, testConfigFile),
+			wantSlots:  30,
+			wantSource: testConfigFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.current.ApplyReload(tt.next)
+
+			if result.Config.ParkingSlotsCount != tt.wantSlots {
+				t.Errorf("ParkingSlotsCount = %d, want %d", result.Config.ParkingSlotsCount, tt.wantSlots)
+			}
+			if changed := slices.Contains(result.Changed, "PARKING_SLOTS_COUNT"); changed != tt.wantChanged {
+				t.Errorf("PARKING_SLOTS_COUNT changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if source := result.Config.settings[0].Source; source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestApplyReloadForgetsReplacedRuntimeValue(t *testing.T) {
+	current := slotsConfig(40, testConfigFile).WithSlotsCount(30)
+	reloaded := current.ApplyReload(slotsConfig(45, testConfigFile)).Config
+
+	// The file value now in effect must not be mistaken for an override.
+	result := reloaded.ApplyReload(slotsConfig(50, testConfigFile))
+	if result.Config.ParkingSlotsCount != 50 {
+		t.Errorf("ParkingSlotsCount = %d, want 50", result.Config.ParkingSlotsCount)
+	}
+}
+
+func TestApplyReloadReportsShadowedSettings(t *testing.T) {
+	next := slotsConfig(40, SourceEnv)
+	next.shadowed = map[string]bool{"PARKING_SLOTS_COUNT": true}
+
+	result := slotsConfig(40, SourceEnv).ApplyReload(next)
+	if !slices.Equal(result.ShadowedByEnv, []string{"PARKING_SLOTS_COUNT"}) {
+		t.Errorf("ShadowedByEnv = %v, want [PARKING_SLOTS_COUNT]", result.ShadowedByEnv)
+	}
+	if len(result.Changed) != 0 {
+		t.Errorf("Changed = %v, want none", result.Changed)
+	}
+}
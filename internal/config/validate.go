@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes one invalid setting.
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Key + " " + e.Message
+}
+
+// ValidationError lists every invalid setting found in the configuration.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+func fieldError(key, format string, args ...any) *FieldError {
+	return &FieldError{Key: key, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate reports settings the service cannot work with as a
+// *ValidationError.
+func (c *Config) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ValidationError{Errors: problems}
+	}
+	return nil
+}
+
+func (c *Config) validate() []*FieldError {
+	var errs []*FieldError
+	if c.ParkingServiceAPIKey == "" {
+		errs = append(errs, fieldError("PARKING_SERVICE_API_KEY", "must be set"))
+	}
+	if c.AdminAPIKey != "" && c.AdminAPIKey == c.ParkingServiceAPIKey {
+		errs = append(errs, fieldError("ADMIN_API_KEY", "must differ from PARKING_SERVICE_API_KEY"))
+	}
+	if c.ParkingSlotsCount <= 0 {
+		errs = append(errs, fieldError("PARKING_SLOTS_COUNT", "must be positive, got %d", c.ParkingSlotsCount))
+	}
+	if c.ParkingHourlyRate < 0 {
+		errs = append(errs, fieldError("PARKING_HOURLY_RATE", "must not be negative, got %d", c.ParkingHourlyRate))
+	}
+	switch c.AllocationStrategy {
+	case AllocationRandom, AllocationLowest:
+	default:
+		errs = append(errs, fieldError("PARKING_ALLOCATION_STRATEGY", "must be random or lowest, got %q", c.AllocationStrategy))
+	}
+	if port, err := strconv.Atoi(c.ServerPort); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fieldError("SERVER_PORT", "must be a port number, got %q", c.ServerPort))
+	}
+	switch strings.ToLower(c.LoggingFormat) {
+	case "text", "json":
+	default:
+		errs = append(errs, fieldError("LOGGING_FORMAT", "must be text or json, got %q", c.LoggingFormat))
+	}
+	switch strings.ToUpper(c.LoggingLevel) {
+	case "DEBUG", "INFO", "WARN", "WARNING", "ERROR", "CRITICAL", "FATAL":
+	default:
+		errs = append(errs, fieldError("LOGGING_LEVEL", "must be DEBUG, INFO, WARNING or ERROR, got %q", c.LoggingLevel))
+	}
+	if c.IdempotencyKeyTTL <= 0 {
+		errs = append(errs, fieldError("IDEMPOTENCY_KEY_TTL", "must be positive, got %s", c.IdempotencyKeyTTL))
+	}
+	if c.OverstayMaxStay < 0 {
+		errs = append(errs, fieldError("OVERSTAY_MAX_STAY", "must not be negative, got %s", c.OverstayMaxStay))
+	}
+	if c.OverstayCheckPeriod <= 0 {
+		errs = append(errs, fieldError("OVERSTAY_CHECK_PERIOD", "must be positive, got %s", c.OverstayCheckPeriod))
+	}
+	for _, zone := range c.PermitZones {
+		if !c.Spaces.HasZone(zone) {
+			errs = append(errs, fieldError("PERMIT_ZONES", "must list zones of PARKING_ZONES, got %q", zone))
+		}
+	}
+	if c.PermitExpiryNotice <= 0 {
+		errs = append(errs, fieldError("PERMIT_EXPIRY_NOTICE", "must be positive, got %s", c.PermitExpiryNotice))
+	}
+	if c.PermitCheckPeriod <= 0 {
+		errs = append(errs, fieldError("PERMIT_CHECK_PERIOD", "must be positive, got %s", c.PermitCheckPeriod))
+	}
+	if c.AuditDenialRetention <= 0 {
+		errs = append(errs, fieldError("AUDIT_DENIAL_RETENTION", "must be positive, got %s", c.AuditDenialRetention))
+	}
+	if c.MetricsRefreshPeriod <= 0 {
+		errs = append(errs, fieldError("METRICS_REFRESH_PERIOD", "must be positive, got %s", c.MetricsRefreshPeriod))
+	}
+	if c.ShutdownDrainDelay < 0 {
+		errs = append(errs, fieldError("SHUTDOWN_DRAIN_DELAY", "must not be negative, got %s", c.ShutdownDrainDelay))
+	}
+	for _, setting := range []struct {
+		key   string
+		value int
+	}{
+		{"RATE_LIMIT_READS_PER_MINUTE", c.RateLimit.ReadsPerMinute},
+		{"RATE_LIMIT_READ_BURST", c.RateLimit.ReadBurst},
+		{"RATE_LIMIT_MUTATIONS_PER_MINUTE", c.RateLimit.MutationsPerMinute},
+		{"RATE_LIMIT_MUTATION_BURST", c.RateLimit.MutationBurst},
+		{"RATE_LIMIT_CLIENT_READS_PER_MINUTE", c.RateLimit.ClientReadsPerMinute},
+		{"RATE_LIMIT_CLIENT_READ_BURST", c.RateLimit.ClientReadBurst},
+		{"RATE_LIMIT_CLIENT_MUTATIONS_PER_MINUTE", c.RateLimit.ClientMutationsPerMinute},
+		{"RATE_LIMIT_CLIENT_MUTATION_BURST", c.RateLimit.ClientMutationBurst},
+		{"RATE_LIMIT_MAX_CLIENTS", c.RateLimit.MaxClients},
+		{"MAX_ACTIVE_SESSIONS_PER_CLIENT", c.MaxActiveSessions},
+	} {
+		if setting.value < 0 {
+			errs = append(errs, fieldError(setting.key, "must not be negative, got %d", setting.value))
+		}
+	}
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				errs = append(errs, fieldError("CORS_ALLOW_ORIGINS", "must list the allowed origins when CORS_ALLOW_CREDENTIALS is enabled, got *"))
+			}
+			continue
+		}
+		if !isOrigin(origin) {
+			errs = append(errs, fieldError("CORS_ALLOW_ORIGINS", "must contain origins such as https://app.example.com, got %q", origin))
+		}
+	}
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				errs = append(errs, fieldError("TRUSTED_PROXIES", "must contain IP addresses or CIDR ranges, got %q", proxy))
+			}
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, fieldError("TLS_KEY_FILE", "must be set together with TLS_CERT_FILE"))
+	}
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		errs = append(errs, fieldError("TLS_CLIENT_CA_FILE", "requires TLS_CERT_FILE"))
+	}
+	switch c.TLS.ClientAuth {
+	case ClientAuthNone, ClientAuthOptional:
+	case ClientAuthRequire:
+		if c.TLS.ClientCAFile == "" {
+			errs = append(errs, fieldError("TLS_CLIENT_AUTH", "require needs TLS_CLIENT_CA_FILE"))
+		}
+	default:
+		errs = append(errs, fieldError("TLS_CLIENT_AUTH", "must be none, optional or require, got %q", c.TLS.ClientAuth))
+	}
+	for _, identity := range c.TLS.AdminIdentities {
+		if !hasIdentity(c.TLS.ClientIdentities, identity) {
+			errs = append(errs, fieldError("TLS_ADMIN_IDENTITIES", "must list identities of TLS_CLIENT_IDENTITIES, got %q", identity))
+		}
+	}
+	if (c.TLS.HealthcheckCertFile == "") != (c.TLS.HealthcheckKeyFile == "") {
+		errs = append(errs, fieldError("TLS_HEALTHCHECK_KEY_FILE", "must be set together with TLS_HEALTHCHECK_CERT_FILE"))
+	}
+	if c.TLS.ReloadPeriod <= 0 {
+		errs = append(errs, fieldError("TLS_RELOAD_PERIOD", "must be positive, got %s", c.TLS.ReloadPeriod))
+	}
+	if c.MongoDB.ConnectMaxAttempts < 0 {
+		errs = append(errs, fieldError("MONGODB_CONNECT_MAX_ATTEMPTS", "must not be negative, got %d", c.MongoDB.ConnectMaxAttempts))
+	}
+	if c.MongoDB.ConnectRetryInitial <= 0 || c.MongoDB.ConnectRetryMax < c.MongoDB.ConnectRetryInitial {
+		errs = append(errs, fieldError("MONGODB_CONNECT_RETRY_INITIAL", "must be positive and not exceed MONGODB_CONNECT_RETRY_MAX"))
+	}
+	if c.MongoDB.HealthCheckPeriod <= 0 {
+		errs = append(errs, fieldError("MONGODB_HEALTH_CHECK_PERIOD", "must be positive, got %s", c.MongoDB.HealthCheckPeriod))
+	}
+	if c.MongoDB.MaxPoolSize < 0 || c.MongoDB.MinPoolSize < 0 || (c.MongoDB.MaxPoolSize > 0 && c.MongoDB.MinPoolSize > c.MongoDB.MaxPoolSize) {
+		errs = append(errs, fieldError("MONGODB_MIN_POOL_SIZE", "must not exceed MONGODB_MAX_POOL_SIZE"))
+	}
+	switch c.MongoDB.ReadConcern {
+	case "", "local", "available", "majority", "linearizable", "snapshot":
+	default:
+		errs = append(errs, fieldError("MONGODB_READ_CONCERN", "%q is not a valid read concern level", c.MongoDB.ReadConcern))
+	}
+	if c.MongoDB.WriteConcern != "" && c.MongoDB.WriteConcern != "majority" {
+		if nodes, err := strconv.Atoi(c.MongoDB.WriteConcern); err != nil || nodes < 0 {
+			errs = append(errs, fieldError("MONGODB_WRITE_CONCERN", "must be \"majority\" or a number of nodes, got %q", c.MongoDB.WriteConcern))
+		}
+	}
+	switch strings.ToLower(c.TracingExporter) {
+	case "", "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fieldError("OTEL_TRACES_EXPORTER", "must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fieldError("OTEL_TRACES_SAMPLE_RATIO", "must be between 0 and 1, got %g", c.TracingSampleRatio))
+	}
+	return errs
+}
+
+// isOrigin reports whether value looks like scheme://host[:port], possibly
+// with * wildcards, and nothing else.
+func isOrigin(value string) bool {
+	scheme, host, found := strings.Cut(value, "://")
+	if !found || (scheme != "http" && scheme != "https") {
+		return false
+	}
+	return host != "" && !strings.ContainsAny(host, "/?#@ ")
+}
+
+func hasIdentity(identities map[string]string, identity string) bool {
+	for _, mapped := range identities {
+		if mapped == identity {
+			return true
+		}
+	}
+	return false
+}
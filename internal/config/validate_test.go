@@ -0,0 +1,210 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// validConfig returns a configuration that passes validation.
+func validConfig() *Config {
+	return &Config{
+		LoggingFormat:        "text",
+		LoggingLevel:         "INFO",
+		ParkingServiceAPIKey: "client-key",
+		ParkingSlotsCount:    52,
+		AllocationStrategy:   AllocationRandom,
+		ServerPort:           "8000",
+		IdempotencyKeyTTL:    24 * time.Hour,
+		OverstayMaxStay:      72 * time.Hour,
+		OverstayCheckPeriod:  time.Minute,
+		PermitExpiryNotice:   168 * time.Hour,
+		PermitCheckPeriod:    time.Hour,
+		AuditDenialRetention: 720 * time.Hour,
+		MetricsRefreshPeriod: 15 * time.Second,
+		MongoDB: MongoDBConfig{
+			ConnectRetryInitial: time.Second,
+			ConnectRetryMax:     30 * time.Second,
+			HealthCheckPeriod:   5 * time.Second,
+			MaxPoolSize:         100,
+		},
+		TLS: TLSConfig{
+			ReloadPeriod: time.Minute,
+			ClientAuth:   ClientAuthOptional,
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(c *Config)
+		wantKeys []string
+	}{
+		{name: "valid", mutate: func(c *Config) {}},
+		{
+			name:     "missing API key",
+			mutate:   func(c *Config) { c.ParkingServiceAPIKey = "" },
+			wantKeys: []string{"PARKING_SERVICE_API_KEY"},
+		},
+		{
+			name:     "no places",
+			mutate:   func(c *Config) { c.ParkingSlotsCount = 0 },
+			wantKeys: []string{"PARKING_SLOTS_COUNT"},
+		},
+		{
+			name:     "negative hourly rate",
+			mutate:   func(c *Config) { c.ParkingHourlyRate = -1 },
+			wantKeys: []string{"PARKING_HOURLY_RATE"},
+		},
+		{
+			name:     "port out of range",
+			mutate:   func(c *Config) { c.ServerPort = "70000" },
+			wantKeys: []string{"SERVER_PORT"},
+		},
+		{
+			name:     "unknown log format",
+			mutate:   func(c *Config) { c.LoggingFormat = "xml" },
+			wantKeys: []string{"LOGGING_FORMAT"},
+		},
+		{
+			name:   "lower-case log level",
+			mutate: func(c *Config) { c.LoggingLevel = "debug" },
+		},
+		{
+			name:     "retry delays out of order",
+			mutate:   func(c *Config) { c.MongoDB.ConnectRetryMax = 0 },
+			wantKeys: []string{"MONGODB_CONNECT_RETRY_INITIAL"},
+		},
+		{
+			name:     "minimum pool above the maximum",
+			mutate:   func(c *Config) { c.MongoDB.MinPoolSize = 200 },
+			wantKeys: []string{"MONGODB_MIN_POOL_SIZE"},
+		},
+		{
+			name:   "write concern as a number of nodes",
+			mutate: func(c *Config) { c.MongoDB.WriteConcern = "2" },
+		},
+		{
+			name:     "unknown write concern",
+			mutate:   func(c *Config) { c.MongoDB.WriteConcern = "all" },
+			wantKeys: []string{"MONGODB_WRITE_CONCERN"},
+		},
+		{
+			name:     "sample ratio above one",
+			mutate:   func(c *Config) { c.TracingSampleRatio = 1.5 },
+			wantKeys: []string{"OTEL_TRACES_SAMPLE_RATIO"},
+		},
+		{
+			name:     "unknown allocation strategy",
+			mutate:   func(c *Config) { c.AllocationStrategy = "first" },
+			wantKeys: []string{"PARKING_ALLOCATION_STRATEGY"},
+		},
+		{
+			name: "negative rate limits",
+			mutate: func(c *Config) {
+				c.RateLimit.ReadBurst = -1
+				c.RateLimit.ClientMutationsPerMinute = -1
+				c.RateLimit.MaxClients = -1
+				c.MaxActiveSessions = -1
+			},
+			wantKeys: []string{"RATE_LIMIT_READ_BURST", "RATE_LIMIT_CLIENT_MUTATIONS_PER_MINUTE", "RATE_LIMIT_MAX_CLIENTS", "MAX_ACTIVE_SESSIONS_PER_CLIENT"},
+		},
+		{
+			name: "origin patterns",
+			mutate: func(c *Config) {
+				c.CORS.AllowOrigins = []string{"https://app.example.com", "https://*.example.org", "http://localhost:*"}
+			},
+		},
+		{
+			name:     "wildcard origin with credentials",
+			mutate:   func(c *Config) { c.CORS.AllowOrigins = []string{"*"}; c.CORS.AllowCredentials = true },
+			wantKeys: []string{"CORS_ALLOW_ORIGINS"},
+		},
+		{
+			name:     "origin with a path",
+			mutate:   func(c *Config) { c.CORS.AllowOrigins = []string{"https://app.example.com/login"} },
+			wantKeys: []string{"CORS_ALLOW_ORIGINS"},
+		},
+		{
+			name:     "certificate without a key",
+			mutate:   func(c *Config) { c.TLS.CertFile = "server.crt" },
+			wantKeys: []string{"TLS_KEY_FILE"},
+		},
+		{
+			name:     "client CA without TLS",
+			mutate:   func(c *Config) { c.TLS.ClientCAFile = "ca.crt" },
+			wantKeys: []string{"TLS_CLIENT_CA_FILE"},
+		},
+		{
+			name:     "required client certificates without a CA",
+			mutate:   func(c *Config) { c.TLS.ClientAuth = ClientAuthRequire },
+			wantKeys: []string{"TLS_CLIENT_AUTH"},
+		},
+		{
+			name:     "permit zone outside the catalogue",
+			mutate:   func(c *Config) { c.PermitZones = []string{"Z"} },
+			wantKeys: []string{"PERMIT_ZONES"},
+		},
+		{
+			name:     "admin key equal to the client key",
+			mutate:   func(c *Config) { c.AdminAPIKey = c.ParkingServiceAPIKey },
+			wantKeys: []string{"ADMIN_API_KEY"},
+		},
+		{
+			name:     "admin identity without a client identity",
+			mutate:   func(c *Config) { c.TLS.AdminIdentities = []string{"ops"} },
+			wantKeys: []string{"TLS_ADMIN_IDENTITIES"},
+		},
+		{
+			name: "admin identity of a client",
+			mutate: func(c *Config) {
+				c.TLS.ClientIdentities = map[string]string{"CN=ops": "ops"}
+				c.TLS.AdminIdentities = []string{"ops"}
+			},
+		},
+		{
+			name:     "healthcheck certificate without a key",
+			mutate:   func(c *Config) { c.TLS.HealthcheckCertFile = "probe.crt" },
+			wantKeys: []string{"TLS_HEALTHCHECK_KEY_FILE"},
+		},
+		{
+			name:   "trusted proxies",
+			mutate: func(c *Config) { c.TrustedProxies = []string{"10.0.0.1", "10.0.0.0/8", "::1"} },
+		},
+		{
+			name:     "trusted proxy host name",
+			mutate:   func(c *Config) { c.TrustedProxies = []string{"proxy.local"} },
+			wantKeys: []string{"TRUSTED_PROXIES"},
+		},
+		{
+			name:     "no denial retention",
+			mutate:   func(c *Config) { c.AuditDenialRetention = 0 },
+			wantKeys: []string{"AUDIT_DENIAL_RETENTION"},
+		},
+		{
+			name: "every problem reported",
+			mutate: func(c *Config) {
+				c.ParkingServiceAPIKey = ""
+				c.ParkingSlotsCount = -1
+				c.OverstayCheckPeriod = 0
+			},
+			wantKeys: []string{"PARKING_SERVICE_API_KEY", "PARKING_SLOTS_COUNT", "OVERSTAY_CHECK_PERIOD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.mutate(cfg)
+
+			var keys []string
+			for _, fieldErr := range cfg.validate() {
+				keys = append(keys, fieldErr.Key)
+			}
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("invalid settings = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
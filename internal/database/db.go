@@ -2,42 +2,196 @@ package database
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 
 	"github.com/amend-parking-backend/internal/config"
 )
 
-var Client *mongo.Client
-var DB *mongo.Database
+// Database is the MongoDB connection of one service instance together with
+// its availability as seen by the last ping.
+type Database struct {
+	Client *mongo.Client
+	DB     *mongo.Database
 
-func InitializeDatabase() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	cfg       config.MongoDBConfig
+	logger    *slog.Logger
+	available atomic.Bool
+}
 
-	var err error
-	Client, err = mongo.Connect(ctx, options.Client().ApplyURI(config.Settings.MongoDBURL))
+// New creates the MongoDB client. The driver connects lazily, so this
+// succeeds even if the server is not reachable yet; use Connect to wait for
+// it. Every command is reported to the given monitors.
+func New(cfg *config.Config, logger *slog.Logger, monitors ...*event.CommandMonitor) (*Database, error) {
+	clientOptions, err := clientOptions(cfg, monitors)
 	if err != nil {
-		log.Printf("Error connecting to MongoDB: %v", err)
-		return err
+		return nil, err
 	}
 
-	err = Client.Ping(ctx, nil)
+	client, err := mongo.Connect(context.Background(), clientOptions)
 	if err != nil {
-		log.Printf("Error pinging MongoDB: %v", err)
-		return err
+		logger.Error("Error connecting to MongoDB", "error", err)
+		return nil, err
+	}
+
+	return &Database{
+		Client: client,
+		DB:     client.Database(cfg.DBName),
+		cfg:    cfg.MongoDB,
+		logger: logger,
+	}, nil
+}
+
+func clientOptions(cfg *config.Config, monitors []*event.CommandMonitor) (*options.ClientOptions, error) {
+	mongoCfg := cfg.MongoDB
+	clientOptions := options.Client().
+		ApplyURI(cfg.MongoDBURL).
+		SetMonitor(combineMonitors(monitors...)).
+		SetMaxPoolSize(uint64(mongoCfg.MaxPoolSize)).
+		SetMinPoolSize(uint64(mongoCfg.MinPoolSize)).
+		SetMaxConnIdleTime(mongoCfg.MaxConnIdleTime).
+		SetConnectTimeout(mongoCfg.ConnectTimeout).
+		SetServerSelectionTimeout(mongoCfg.ServerSelectionTimeout)
+	if mongoCfg.SocketTimeout > 0 {
+		clientOptions.SetSocketTimeout(mongoCfg.SocketTimeout)
+	}
+
+	if mongoCfg.ReadConcern != "" {
+		clientOptions.SetReadConcern(&readconcern.ReadConcern{Level: mongoCfg.ReadConcern})
+	}
+	if mongoCfg.WriteConcern != "" {
+		writeConcern, err := parseWriteConcern(mongoCfg.WriteConcern)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetWriteConcern(writeConcern)
+	}
+
+	return clientOptions, nil
+}
+
+// parseWriteConcern accepts "majority" or the number of acknowledging nodes.
+func parseWriteConcern(value string) (*writeconcern.WriteConcern, error) {
+	if value == "majority" {
+		return writeconcern.Majority(), nil
+	}
+	nodes, err := strconv.Atoi(value)
+	if err != nil || nodes < 0 {
+		return nil, fmt.Errorf("invalid write concern %q", value)
+	}
+	return &writeconcern.WriteConcern{W: nodes}, nil
+}
+
+// Connect pings MongoDB until it answers, waiting with exponential backoff
+// between attempts. It gives up after the configured number of attempts, if
+// any, or when ctx is cancelled.
+func (d *Database) Connect(ctx context.Context) error {
+	delay := d.cfg.ConnectRetryInitial
+
+	for attempt := 1; ; attempt++ {
+		err := d.Ping(ctx)
+		if err == nil {
+			d.logger.Info("Database initialized successfully", "attempt", attempt)
+			return nil
+		}
+		if d.cfg.ConnectMaxAttempts > 0 && attempt >= d.cfg.ConnectMaxAttempts {
+			return fmt.Errorf("MongoDB is not reachable after %d attempts: %w", attempt, err)
+		}
+
+		d.logger.Warn("MongoDB is not reachable, retrying", "attempt", attempt, "retry_in", delay.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > d.cfg.ConnectRetryMax {
+			delay = d.cfg.ConnectRetryMax
+		}
 	}
+}
+
+// RunAvailabilityMonitor keeps IsAvailable up to date until ctx is cancelled.
+func (d *Database) RunAvailabilityMonitor(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.HealthCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		wasAvailable := d.available.Load()
+		err := d.Ping(ctx)
+		if err != nil && wasAvailable && ctx.Err() == nil {
+			d.logger.Error("MongoDB became unavailable", "error", err)
+		}
+		if err == nil && !wasAvailable {
+			d.logger.Info("MongoDB is available again")
+		}
+	}
+}
 
-	DB = Client.Database(config.Settings.DBName)
-	log.Println("Database initialized successfully.")
-	return nil
+// IsAvailable reports whether the last ping of MongoDB succeeded.
+func (d *Database) IsAvailable() bool {
+	return d.available.Load()
 }
 
-func CloseDatabase() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// RetryAfter is how long clients should wait before retrying while the
+// database is unavailable.
+func (d *Database) RetryAfter() time.Duration {
+	return d.cfg.HealthCheckPeriod
+}
+
+func (d *Database) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.ConnectTimeout)
 	defer cancel()
-	return Client.Disconnect(ctx)
+
+	err := d.Client.Ping(ctx, nil)
+	d.available.Store(err == nil)
+	return err
+}
+
+func (d *Database) Close(ctx context.Context) error {
+	return d.Client.Disconnect(ctx)
+}
+
+// combineMonitors fans command events out to several monitors, since the
+// driver accepts only one.
+func combineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Started != nil {
+					monitor.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				if monitor.Succeeded != nil {
+					monitor.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Failed != nil {
+					monitor.Failed(ctx, evt)
+				}
+			}
+		},
+	}
 }
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SessionOverstayed = "session.overstayed"
+	PermitExpiring    = "permit.expiring"
+)
+
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Publisher logs domain events and delivers them to the configured webhook.
+type Publisher struct {
+	webhookURL string
+	client     *http.Client
+	logger     *slog.Logger
+}
+
+func NewPublisher(webhookURL string, logger *slog.Logger) *Publisher {
+	return &Publisher{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		logger:     logger,
+	}
+}
+
+func (p *Publisher) Publish(ctx context.Context, eventType string, data interface{}) {
+	event := Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+	p.logger.InfoContext(ctx, "Event published", "event_type", event.Type, "event_id", event.ID)
+
+	if p.webhookURL == "" {
+		return
+	}
+	if err := p.deliver(ctx, event); err != nil {
+		p.logger.ErrorContext(ctx, "Error delivering event to webhook", "event_id", event.ID, "error", err)
+	}
+}
+
+func (p *Publisher) deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+var ErrShuttingDown = errors.New("service is shutting down")
+
+// CheckFunc reports whether a dependency is ready to serve requests.
+type CheckFunc func(ctx context.Context) error
+
+type CheckResult struct {
+	Status string `json:"status" example:"ok"`
+	Error  string `json:"error,omitempty" example:""`
+}
+
+type Report struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker aggregates the readiness checks of the service.
+type Checker struct {
+	mu           sync.RWMutex
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+	timeout      time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: 2 * time.Second}
+}
+
+func (c *Checker) AddReadinessCheck(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes the service report itself as not ready, so that
+// traffic is drained before the server stops accepting connections.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+func (c *Checker) Liveness() Report {
+	return Report{Status: StatusOK}
+}
+
+// Readiness runs all readiness checks concurrently.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = result(check.check(ctx))
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks)+1)}
+	for i, check := range checks {
+		report.Checks[check.name] = results[i]
+	}
+	shutdown := CheckResult{Status: StatusOK}
+	if c.shuttingDown.Load() {
+		shutdown = result(ErrShuttingDown)
+	}
+	report.Checks["shutdown"] = shutdown
+
+	for _, checkResult := range report.Checks {
+		if checkResult.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func result(err error) CheckResult {
+	if err != nil {
+		return CheckResult{Status: StatusFail, Error: err.Error()}
+	}
+	return CheckResult{Status: StatusOK}
+}
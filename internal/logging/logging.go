@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/amend-parking-backend/internal/config"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+
+	redacted = "[REDACTED]"
+)
+
+// personalDataKeys are attribute keys whose values are never written to the
+// log, wherever they appear.
+var personalDataKeys = map[string]bool{
+	"first_name":    true,
+	"last_name":     true,
+	"license_plate": true,
+}
+
+// New builds the application logger from the LOGGING_* settings. The
+// returned level can be changed while the logger is in use.
+func New(cfg *config.Config) (*slog.Logger, *slog.LevelVar) {
+	return newLogger(os.Stdout, cfg)
+}
+
+func newLogger(w io.Writer, cfg *config.Config) (*slog.Logger, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	level.Set(ParseLevel(cfg.LoggingLevel))
+
+	dateFormat := cfg.LoggingDateFormat
+	options := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey && dateFormat != "" {
+				return slog.String(slog.TimeKey, attr.Value.Time().Format(dateFormat))
+			}
+			if personalDataKeys[attr.Key] {
+				return slog.String(attr.Key, redacted)
+			}
+			return attr
+		},
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.LoggingFormat, FormatJSON) {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	return slog.New(contextHandler{handler}), level
+}
+
+// ParseLevel accepts slog level names as well as Python ones such as WARNING
+// and CRITICAL. Unknown names fall back to INFO.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return slog.LevelDebug
+	case "WARN", "WARNING":
+		return slog.LevelWarn
+	case "ERROR", "CRITICAL", "FATAL":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID and the trace and span IDs stored in the
+// context to every record logged with one of the *Context methods.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Duration formats d as fractional milliseconds for log attributes.
+func Duration(d time.Duration) slog.Attr {
+	return slog.Float64("duration_ms", float64(d.Microseconds())/1000)
+}
@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "parking"
+
+const (
+	AllocationFailureLotFull      = "lot_full"
+	AllocationFailureClientLimit  = "client_limit"
+	AllocationFailureAccessDenied = "access_denied"
+)
+
+// Metrics holds the collectors of one service instance in its own registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	HTTPRequestDuration     *prometheus.HistogramVec
+	MongoOperationDuration  *prometheus.HistogramVec
+	CarsParkedTotal         *prometheus.CounterVec
+	SpacesFreedTotal        *prometheus.CounterVec
+	AllocationFailuresTotal *prometheus.CounterVec
+	SessionDuration         *prometheus.HistogramVec
+	RateLimitedTotal        *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duration of HTTP requests by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+
+		MongoOperationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "mongodb_operation_duration_seconds",
+			Help:      "Duration of MongoDB commands issued by the repository.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"command", "collection", "outcome"}),
+
+		CarsParkedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cars_parked_total",
+			Help:      "Number of parking sessions started.",
+		}, []string{"lot"}),
+
+		SpacesFreedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "spaces_freed_total",
+			Help:      "Number of parking sessions ended.",
+		}, []string{"lot"}),
+
+		AllocationFailuresTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "allocation_failures_total",
+			Help:      "Number of park requests rejected because no place could be allocated or the car was turned away.",
+		}, []string{"lot", "reason"}),
+
+		SessionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "session_duration_seconds",
+			Help:      "Duration of ended parking sessions.",
+			Buckets: []float64{
+				(15 * time.Minute).Seconds(),
+				(30 * time.Minute).Seconds(),
+				time.Hour.Seconds(),
+				(2 * time.Hour).Seconds(),
+				(4 * time.Hour).Seconds(),
+				(8 * time.Hour).Seconds(),
+				(12 * time.Hour).Seconds(),
+				(24 * time.Hour).Seconds(),
+				(72 * time.Hour).Seconds(),
+				(7 * 24 * time.Hour).Seconds(),
+			},
+		}, []string{"lot"}),
+
+		RateLimitedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_requests_total",
+			Help:      "Number of requests rejected by the rate limiter.",
+		}, []string{"class", "scope"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequestDuration,
+		m.MongoOperationDuration,
+		m.CarsParkedTotal,
+		m.SpacesFreedTotal,
+		m.AllocationFailuresTotal,
+		m.SessionDuration,
+		m.RateLimitedTotal,
+	)
+	return m
+}
+
+var (
+	occupiedSpacesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "occupied_spaces"),
+		"Number of occupied parking spaces.",
+		[]string{"lot"}, nil,
+	)
+	freeSpacesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "free_spaces"),
+		"Number of free parking spaces.",
+		[]string{"lot"}, nil,
+	)
+)
+
+// OccupancyFunc reports the current number of occupied and free spaces of a
+// lot.
+type OccupancyFunc func(ctx context.Context) (occupied, free int, err error)
+
+// Occupancy exposes the occupied and free space gauges of a lot. The values
+// are read from the database by Run on a timer and cached, so that scrapes of
+// the unauthenticated /metrics endpoint never query MongoDB.
+type Occupancy struct {
+	lot       string
+	occupancy OccupancyFunc
+
+	mu       sync.Mutex
+	occupied int
+	free     int
+	err      error
+}
+
+// RegisterOccupancy exposes the occupied and free space gauges of lot. They
+// are missing from scrapes until the first Refresh.
+func (m *Metrics) RegisterOccupancy(lot string, occupancy OccupancyFunc) *Occupancy {
+	o := &Occupancy{lot: lot, occupancy: occupancy, err: errors.New("occupancy not read yet")}
+	m.registry.MustRegister(o)
+	return o
+}
+
+// Run refreshes the gauges every period until ctx is cancelled.
+func (o *Occupancy) Run(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		o.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh reads the occupancy from the database. If that fails, the gauges
+// are dropped from scrapes until the next successful refresh.
+func (o *Occupancy) Refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	occupied, free, err := o.occupancy(ctx)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.occupied, o.free, o.err = occupied, free, err
+}
+
+func (o *Occupancy) Describe(ch chan<- *prometheus.Desc) {
+	ch <- occupiedSpacesDesc
+	ch <- freeSpacesDesc
+}
+
+func (o *Occupancy) Collect(ch chan<- prometheus.Metric) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.err != nil {
+		ch <- prometheus.NewInvalidMetric(occupiedSpacesDesc, o.err)
+		ch <- prometheus.NewInvalidMetric(freeSpacesDesc, o.err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(occupiedSpacesDesc, prometheus.GaugeValue, float64(o.occupied), o.lot)
+	ch <- prometheus.MustNewConstMetric(freeSpacesDesc, prometheus.GaugeValue, float64(o.free), o.lot)
+}
+
+// Handler serves all registered metrics. A failed occupancy refresh, e.g.
+// while MongoDB is down, only drops the affected gauges from the scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		m.registry,
+		promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+			ErrorHandling: promhttp.ContinueOnError,
+		}),
+	)
+}
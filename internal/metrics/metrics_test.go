@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOccupancyServedFromCache(t *testing.T) {
+	calls := 0
+	var occupancyErr error
+	m := New()
+	occupancy := m.RegisterOccupancy("main", func(context.Context) (int, int, error) {
+		calls++
+		return 3, 7, occupancyErr
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		m.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		return recorder.Body.String()
+	}
+
+	tests := []struct {
+		name      string
+		refresh   bool
+		err       error
+		wantCalls int
+		wantGauge bool
+	}{
+		{name: "before the first refresh", wantCalls: 0},
+		{name: "after a refresh", refresh: true, wantCalls: 1, wantGauge: true},
+		{name: "scrape without a refresh", wantCalls: 1, wantGauge: true},
+		{name: "failed refresh", refresh: true, err: errors.New("database down"), wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occupancyErr = tt.err
+			if tt.refresh {
+				occupancy.Refresh(context.Background())
+			}
+			body := scrape()
+			scrape()
+
+			if calls != tt.wantCalls {
+				t.Errorf("occupancy queries = %d, want %d", calls, tt.wantCalls)
+			}
+			if got := strings.Contains(body, `parking_free_spaces{lot="main"} 7`); got != tt.wantGauge {
+				t.Errorf("free spaces gauge exposed = %v, want %v", got, tt.wantGauge)
+			}
+		})
+	}
+}
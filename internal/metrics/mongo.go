@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor returns a command monitor that records the latency of every
+// MongoDB command by command name and collection.
+func (m *Metrics) MongoMonitor() *event.CommandMonitor {
+	var collections sync.Map
+
+	observe := func(requestID int64, command string, duration time.Duration, outcome string) {
+		collection := ""
+		if value, ok := collections.LoadAndDelete(requestID); ok {
+			collection = value.(string)
+		}
+		m.MongoOperationDuration.WithLabelValues(command, collection, outcome).Observe(duration.Seconds())
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			// The first element of a command document holds the collection name,
+			// e.g. {find: "parking_space_logs", ...}.
+			if element, err := evt.Command.IndexErr(0); err == nil {
+				if collection, ok := element.Value().StringValueOK(); ok {
+					collections.Store(evt.RequestID, collection)
+				}
+			}
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			observe(evt.RequestID, evt.CommandName, evt.Duration, "success")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			observe(evt.RequestID, evt.CommandName, evt.Duration, "failure")
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionName = "migrations"
+
+// Migration is one versioned schema change. Up and Down must be idempotent:
+// several instances may apply the same migration at the same time, and a
+// failed run is simply retried.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+	Down        func(ctx context.Context, db *mongo.Database) error
+}
+
+// Status describes a migration and whether it has been applied.
+type Status struct {
+	Version     int
+	Description string
+	AppliedAt   *time.Time
+}
+
+type record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrator applies and rolls back migrations, recording the applied ones in
+// the migrations collection.
+type Migrator struct {
+	db         *mongo.Database
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+func NewMigrator(db *mongo.Database, logger *slog.Logger) *Migrator {
+	return &Migrator{db: db, logger: logger, migrations: all}
+}
+
+// Up applies all pending migrations in version order and returns how many
+// were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range pending(m.migrations, applied) {
+		m.logger.InfoContext(ctx, "Applying migration", "version", migration.Version, "description", migration.Description)
+		if err := migration.Up(ctx, m.db); err != nil {
+			return count, fmt.Errorf("apply migration %d: %w", migration.Version, err)
+		}
+
+		_, err := m.collection().UpdateOne(ctx,
+			bson.M{"_id": migration.Version},
+			bson.M{"$setOnInsert": record{
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now().UTC(),
+			}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return count, fmt.Errorf("record migration %d: %w", migration.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first, and
+// returns how many were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range rollbacks(m.migrations, applied, steps) {
+		m.logger.InfoContext(ctx, "Rolling back migration", "version", migration.Version, "description", migration.Description)
+		if err := migration.Down(ctx, m.db); err != nil {
+			return count, fmt.Errorf("roll back migration %d: %w", migration.Version, err)
+		}
+		if _, err := m.collection().DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return count, fmt.Errorf("record rollback of migration %d: %w", migration.Version, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status lists every known migration in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses(m.migrations, applied), nil
+}
+
+// Check is a readiness check that fails while migrations are pending.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var versions []int
+	for _, migration := range pending(m.migrations, applied) {
+		versions = append(versions, migration.Version)
+	}
+	if len(versions) > 0 {
+		return fmt.Errorf("pending migrations: %v", versions)
+	}
+	return nil
+}
+
+// pending returns the migrations that have not been applied, in version
+// order.
+func pending(migrations []Migration, applied map[int]record) []Migration {
+	var result []Migration
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
+
+// rollbacks returns the last steps applied migrations, newest first.
+func rollbacks(migrations []Migration, applied map[int]record, steps int) []Migration {
+	var result []Migration
+	for i := len(migrations) - 1; i >= 0 && len(result) < steps; i-- {
+		if _, ok := applied[migrations[i].Version]; ok {
+			result = append(result, migrations[i])
+		}
+	}
+	return result
+}
+
+func statuses(migrations []Migration, applied map[int]record) []Status {
+	result := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Version: migration.Version, Description: migration.Description}
+		if r, ok := applied[migration.Version]; ok {
+			appliedAt := r.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+	return result
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]record, error) {
+	cursor, err := m.collection().Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]record, len(records))
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func (m *Migrator) collection() *mongo.Collection {
+	return m.db.Collection(collectionName)
+}
@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAllMigrationsAreNumberedInOrder(t *testing.T) {
+	for i, migration := range all {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d has version %d, want %d", i, migration.Version, i+1)
+		}
+		if migration.Description == "" || migration.Up == nil || migration.Down == nil {
+			t.Errorf("migration %d lacks a description, Up or Down", migration.Version)
+		}
+	}
+}
+
+func testMigrations(versions ...int) []Migration {
+	migrations := make([]Migration, len(versions))
+	for i, version := range versions {
+		migrations[i] = Migration{Version: version}
+	}
+	return migrations
+}
+
+func appliedVersions(versions ...int) map[int]record {
+	applied := make(map[int]record, len(versions))
+	for _, version := range versions {
+		applied[version] = record{Version: version, AppliedAt: time.Date(2024, 1, version, 0, 0, 0, 0, time.UTC)}
+	}
+	return applied
+}
+
+func versionsOf(migrations []Migration) []int {
+	var versions []int
+	for _, migration := range migrations {
+		versions = append(versions, migration.Version)
+	}
+	return versions
+}
+
+func TestPending(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied map[int]record
+		want    []int
+	}{
+		{name: "fresh database", applied: appliedVersions(), want: []int{1, 2, 3, 4}},
+		{name: "partly applied", applied: appliedVersions(1, 2), want: []int{3, 4}},
+		{name: "gap left by a failed run", applied: appliedVersions(1, 3), want: []int{2, 4}},
+		{name: "up to date", applied: appliedVersions(1, 2, 3, 4)},
+		{name: "unknown applied version ignored", applied: appliedVersions(1, 2, 3, 4, 5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionsOf(pending(testMigrations(1, 2, 3, 4), tt.applied)); !slices.Equal(got, tt.want) {
+				t.Errorf("pending() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRollbacks(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied map[int]record
+		steps   int
+		want    []int
+	}{
+		{name: "last one", applied: appliedVersions(1, 2, 3, 4), steps: 1, want: []int{4}},
+		{name: "newest first", applied: appliedVersions(1, 2, 3, 4), steps: 3, want: []int{4, 3, 2}},
+		{name: "more steps than applied", applied: appliedVersions(1, 2), steps: 10, want: []int{2, 1}},
+		{name: "pending ones skipped", applied: appliedVersions(1, 3), steps: 2, want: []int{3, 1}},
+		{name: "no steps", applied: appliedVersions(1, 2), steps: 0},
+		{name: "nothing applied", applied: appliedVersions(), steps: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := versionsOf(rollbacks(testMigrations(1, 2, 3, 4), tt.applied, tt.steps)); !slices.Equal(got, tt.want) {
+				t.Errorf("rollbacks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	applied := appliedVersions(1, 3)
+	got := statuses(testMigrations(1, 2, 3), applied)
+
+	if versions := []int{got[0].Version, got[1].Version, got[2].Version}; !slices.Equal(versions, []int{1, 2, 3}) {
+		t.Fatalf("versions = %v, want [1 2 3]", versions)
+	}
+	for _, status := range got {
+		r, ok := applied[status.Version]
+		switch {
+		case !ok && status.AppliedAt != nil:
+			t.Errorf("migration %d reported as applied", status.Version)
+		case ok && (status.AppliedAt == nil || !status.AppliedAt.Equal(r.AppliedAt)):
+			t.Errorf("migration %d applied at %v, want %v", status.Version, status.AppliedAt, r.AppliedAt)
+		}
+	}
+}
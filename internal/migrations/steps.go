@@ -0,0 +1,294 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/amend-parking-backend/internal/repository"
+)
+
+// all lists the migrations in version order. Never change or renumber a
+// migration once it has been released; add a new one instead.
+var all = []Migration{
+	{
+		Version:     1,
+		Description: "index active parking sessions by place number and log ID",
+		Up: createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "is_active", Value: 1}, {Key: "place_number", Value: 1}},
+				Options: options.Index().SetName("is_active_place_number"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "log_id", Value: 1}},
+				Options: options.Index().SetName("log_id_unique").SetUnique(true),
+			},
+		),
+		Down: dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "is_active_place_number", "log_id_unique"),
+	},
+	{
+		Version:     2,
+		Description: "index parking sessions by driver name, case-insensitively",
+		Up: createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+			mongo.IndexModel{
+				Keys: bson.D{{Key: "last_name", Value: 1}, {Key: "first_name", Value: 1}, {Key: "is_active", Value: 1}},
+				Options: options.Index().
+					SetName("last_name_first_name_ci").
+					SetCollation(repository.NameCollation),
+			},
+		),
+		Down: dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "last_name_first_name_ci"),
+	},
+	{
+		Version:     3,
+		Description: "index active parking sessions by start time for overstay detection",
+		Up: createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "is_active", Value: 1}, {Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("is_active_created_at"),
+			},
+		),
+		Down: dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "is_active_created_at"),
+	},
+	{
+		// Earlier versions created this index on startup under the default
+		// name, so the name must stay the same for the step to be a no-op there.
+		Version:     4,
+		Description: "expire idempotency keys",
+		Up: createIndexes(models.IdempotencyKey{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_1").SetExpireAfterSeconds(0),
+			},
+		),
+		Down: dropIndexes(models.IdempotencyKey{}.CollectionName(), "expires_at_1"),
+	},
+	{
+		Version:     5,
+		Description: "index active parking sessions by the client that started them",
+		Up: createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "created_by", Value: 1}, {Key: "is_active", Value: 1}},
+				Options: options.Index().SetName("created_by_is_active"),
+			},
+		),
+		Down: dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "created_by_is_active"),
+	},
+	{
+		Version:     6,
+		Description: "index maintenance blocks in effect",
+		Up: createIndexes(models.MaintenanceBlock{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "ended_at", Value: 1}, {Key: "until", Value: 1}, {Key: "from", Value: 1}},
+				Options: options.Index().SetName("ended_at_until_from"),
+			},
+		),
+		Down: dropIndexes(models.MaintenanceBlock{}.CollectionName(), "ended_at_until_from"),
+	},
+	{
+		Version:     7,
+		Description: "index registered vehicles by unique plate and by driver",
+		Up: createIndexes(models.Vehicle{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "license_plate", Value: 1}},
+				Options: options.Index().SetName("license_plate_unique").SetUnique(true),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "driver_id", Value: 1}},
+				Options: options.Index().SetName("driver_id"),
+			},
+		),
+		Down: dropIndexes(models.Vehicle{}.CollectionName(), "license_plate_unique", "driver_id"),
+	},
+	{
+		Version:     8,
+		Description: "index permits by validity and by holder",
+		Up: createIndexes(models.Permit{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "lot_id", Value: 1}, {Key: "valid_until", Value: 1}},
+				Options: options.Index().SetName("lot_id_valid_until"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "vehicle_id", Value: 1}},
+				Options: options.Index().SetName("vehicle_id"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "driver_id", Value: 1}},
+				Options: options.Index().SetName("driver_id"),
+			},
+		),
+		Down: dropIndexes(models.Permit{}.CollectionName(), "lot_id_valid_until", "vehicle_id", "driver_id"),
+	},
+	{
+		Version:     9,
+		Description: "index plate lists by unique plate",
+		Up: createIndexes(models.PlateListEntry{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "lot_id", Value: 1}, {Key: "list", Value: 1}, {Key: "license_plate", Value: 1}},
+				Options: options.Index().SetName("lot_id_list_license_plate_unique").SetUnique(true),
+			},
+		),
+		Down: dropIndexes(models.PlateListEntry{}.CollectionName(), "lot_id_list_license_plate_unique"),
+	},
+	{
+		Version:     10,
+		Description: "index the audit trail by time",
+		Up: createIndexes(models.AuditEntry{}.CollectionName(),
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "lot_id", Value: 1}, {Key: "at", Value: -1}},
+				Options: options.Index().SetName("lot_id_at"),
+			},
+		),
+		Down: dropIndexes(models.AuditEntry{}.CollectionName(), "lot_id_at"),
+	},
+	{
+		// Replaces the index of version 1, which let two active sessions
+		// hold the same place when requests raced. The step fails while such
+		// sessions exist; end or move them first.
+		Version:     11,
+		Description: "allow a single active parking session per place",
+		Up: inOrder(
+			createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "place_number", Value: 1}},
+					Options: options.Index().
+						SetName("active_place_number_unique").
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"is_active": true}),
+				},
+			),
+			dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "is_active_place_number"),
+		),
+		Down: inOrder(
+			createIndexes(models.ParkingSpaceLog{}.CollectionName(),
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "is_active", Value: 1}, {Key: "place_number", Value: 1}},
+					Options: options.Index().SetName("is_active_place_number"),
+				},
+			),
+			dropIndexes(models.ParkingSpaceLog{}.CollectionName(), "active_place_number_unique"),
+		),
+	},
+	{
+		Version:     12,
+		Description: "collapse repeated denials in the audit trail and expire them",
+		Up: createIndexes(models.AuditEntry{}.CollectionName(),
+			mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "lot_id", Value: 1},
+					{Key: "action", Value: 1},
+					{Key: "license_plate", Value: 1},
+					{Key: "at", Value: -1},
+				},
+				Options: options.Index().SetName("lot_id_action_license_plate_at"),
+			},
+			mongo.IndexModel{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at_1").SetExpireAfterSeconds(0),
+			},
+		),
+		Down: dropIndexes(models.AuditEntry{}.CollectionName(), "lot_id_action_license_plate_at", "expires_at_1"),
+	},
+	{
+		// The service keeps the times up to date from this version on; the
+		// step takes over those of the sessions ended or moved before.
+		Version:     13,
+		Description: "record when each place was last vacated",
+		Up:          backfillPlaceVacatedTimes,
+		Down:        dropCollection(models.PlaceState{}.CollectionName()),
+	},
+}
+
+// backfillPlaceVacatedTimes derives the time each place was last vacated
+// from the session history, keeping later times already recorded.
+func backfillPlaceVacatedTimes(ctx context.Context, db *mongo.Database) error {
+	ended := bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$is_active", false}},
+		bson.A{bson.M{"place": "$place_number", "at": "$free_up_time"}},
+		bson.A{},
+	}}
+	movedAway := bson.M{"$map": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$moves", bson.A{}}},
+		"as":    "move",
+		"in":    bson.M{"place": "$$move.from_place", "at": "$$move.moved_at"},
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": bson.A{
+			bson.M{"is_active": false},
+			bson.M{"moves.0": bson.M{"$exists": true}},
+		}}}},
+		{{Key: "$project", Value: bson.M{"vacated": bson.M{"$concatArrays": bson.A{ended, movedAway}}}}},
+		{{Key: "$unwind", Value: "$vacated"}},
+		{{Key: "$match", Value: bson.M{"vacated.at": bson.M{"$type": "date"}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$vacated.place", "last_vacated_at": bson.M{"$max": "$vacated.at"}}}},
+		{{Key: "$merge", Value: bson.M{
+			"into": models.PlaceState{}.CollectionName(),
+			"on":   "_id",
+			"whenMatched": bson.A{bson.M{"$set": bson.M{
+				"last_vacated_at": bson.M{"$max": bson.A{"$last_vacated_at", "$$new.last_vacated_at"}},
+			}}},
+			"whenNotMatched": "insert",
+		}}},
+	}
+	cursor, err := db.Collection(models.ParkingSpaceLog{}.CollectionName()).Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	return cursor.Close(ctx)
+}
+
+// dropCollection returns a step dropping the collection. Dropping a
+// collection that does not exist does nothing.
+func dropCollection(collection string) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		return db.Collection(collection).Drop(ctx)
+	}
+}
+
+// inOrder returns a step running the steps one after another.
+func inOrder(steps ...func(context.Context, *mongo.Database) error) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, step := range steps {
+			if err := step(ctx, db); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// createIndexes returns a step creating the indexes. Creating an index that
+// already exists with the same options does nothing.
+func createIndexes(collection string, indexes ...mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+// dropIndexes returns a step dropping the named indexes, ignoring those that
+// do not exist.
+func dropIndexes(collection string, names ...string) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, name := range names {
+			_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+			if err != nil && !isNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func isNotFound(err error) bool {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) {
+		// IndexNotFound and NamespaceNotFound.
+		return commandErr.Code == 27 || commandErr.Code == 26
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "index not found", err: mongo.CommandError{Code: 27, Name: "IndexNotFound"}, want: true},
+		{name: "namespace not found", err: mongo.CommandError{Code: 26, Name: "NamespaceNotFound"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("drop: %w", mongo.CommandError{Code: 27}), want: true},
+		{name: "other command error", err: mongo.CommandError{Code: 13, Name: "Unauthorized"}},
+		{name: "other error", err: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isNotFound(tt.err); got != tt.want {
+				t.Errorf("isNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInOrder(t *testing.T) {
+	errStep := errors.New("step failed")
+	var ran []int
+	step := func(n int, err error) func(context.Context, *mongo.Database) error {
+		return func(context.Context, *mongo.Database) error {
+			ran = append(ran, n)
+			return err
+		}
+	}
+
+	tests := []struct {
+		name    string
+		steps   []func(context.Context, *mongo.Database) error
+		wantRan []int
+		wantErr error
+	}{
+		{name: "all steps in order", steps: []func(context.Context, *mongo.Database) error{step(1, nil), step(2, nil), step(3, nil)}, wantRan: []int{1, 2, 3}},
+		{name: "stops at the first error", steps: []func(context.Context, *mongo.Database) error{step(1, nil), step(2, errStep), step(3, nil)}, wantRan: []int{1, 2}, wantErr: errStep},
+		{name: "no steps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			err := inOrder(tt.steps...)(context.Background(), nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ran, tt.wantRan) {
+				t.Errorf("ran = %v, want %v", ran, tt.wantRan)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Access modes of a lot.
+const (
+	// AccessOpen admits every vehicle that is not on the deny list.
+	AccessOpen = "open"
+	// AccessAllowList admits only registered vehicles and plates on the allow
+	// list, unless they are on the deny list.
+	AccessAllowList = "allow_list"
+)
+
+// Plate lists of a lot.
+const (
+	PlateListDeny  = "deny"
+	PlateListAllow = "allow"
+)
+
+// AccessPolicy is the access mode of a lot. A lot without a policy is open.
+type AccessPolicy struct {
+	LotID     string    `bson:"_id" json:"lot_id" example:"main"`
+	Mode      string    `bson:"mode" json:"mode" example:"allow_list" enums:"open,allow_list"`
+	UpdatedBy string    `bson:"updated_by,omitempty" json:"updated_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at" example:"2024-01-01T09:00:00Z"`
+}
+
+func (p AccessPolicy) CollectionName() string {
+	return "access_policies"
+}
+
+// PlateListEntry puts a normalised license plate on the deny or allow list of
+// a lot.
+type PlateListEntry struct {
+	EntryID      string    `bson:"_id" json:"entry_id" example:"2f7d9b3e-1c4a-4e6b-8d0f-5a3c7e9b1d2f"`
+	LotID        string    `bson:"lot_id" json:"lot_id" example:"main"`
+	List         string    `bson:"list" json:"list" example:"deny" enums:"deny,allow"`
+	LicensePlate string    `bson:"license_plate" json:"license_plate" example:"А123ВС777"`
+	Reason       string    `bson:"reason,omitempty" json:"reason,omitempty" example:"Неоплаченные штрафы"`
+	CreatedBy    string    `bson:"created_by,omitempty" json:"created_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at" example:"2024-01-01T09:00:00Z"`
+}
+
+func (e PlateListEntry) CollectionName() string {
+	return "plate_list_entries"
+}
+
+// Actions recorded in the audit trail.
+const (
+	AuditAccessModeChanged = "access_mode.changed"
+	AuditPlateListed       = "plate.listed"
+	AuditPlateUnlisted     = "plate.unlisted"
+	AuditParkDenied        = "park.denied"
+)
+
+// AuditEntry records a change of the access rules of a lot, or a car turned
+// away by them. The fields beyond Action are set as far as they apply.
+// Repeated denials of the same plate for the same reason are collapsed into
+// one entry: Count is the number of denials since At, the last one at LastAt.
+// Denials expire at ExpiresAt, while changes are kept.
+type AuditEntry struct {
+	EntryID      string     `bson:"_id" json:"entry_id" example:"6c1e8a4d-3b7f-4d2e-9a5c-0f8b2d4e6a1c"`
+	LotID        string     `bson:"lot_id" json:"lot_id" example:"main"`
+	Action       string     `bson:"action" json:"action" example:"plate.listed" enums:"access_mode.changed,plate.listed,plate.unlisted,park.denied"`
+	Actor        string     `bson:"actor,omitempty" json:"actor,omitempty" example:"key:3f2a9c1b7d4e"`
+	Mode         string     `bson:"mode,omitempty" json:"mode,omitempty" example:"allow_list"`
+	List         string     `bson:"list,omitempty" json:"list,omitempty" example:"deny"`
+	LicensePlate string     `bson:"license_plate,omitempty" json:"license_plate,omitempty" example:"А123ВС777"`
+	Reason       string     `bson:"reason,omitempty" json:"reason,omitempty" example:"Неоплаченные штрафы"`
+	At           time.Time  `bson:"at" json:"at" example:"2024-01-01T09:00:00Z"`
+	Count        int        `bson:"count,omitempty" json:"count,omitempty" example:"3"`
+	LastAt       *time.Time `bson:"last_at,omitempty" json:"last_at,omitempty" example:"2024-01-01T09:20:00Z"`
+	ExpiresAt    *time.Time `bson:"expires_at,omitempty" json:"-"`
+}
+
+func (e AuditEntry) CollectionName() string {
+	return "audit_log"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// IdempotencyKey remembers the outcome of a mutating request sent with an
+// Idempotency-Key header so that retries of the same request get the same
+// response instead of being executed again.
+type IdempotencyKey struct {
+	Key          string    `bson:"_id"`
+	RequestHash  string    `bson:"request_hash"`
+	Completed    bool      `bson:"completed"`
+	StatusCode   int       `bson:"status_code,omitempty"`
+	ContentType  string    `bson:"content_type,omitempty"`
+	Location     string    `bson:"location,omitempty"`
+	ResponseBody []byte    `bson:"response_body,omitempty"`
+	CreatedAt    time.Time `bson:"created_at"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+func (k IdempotencyKey) CollectionName() string {
+	return "idempotency_keys"
+}
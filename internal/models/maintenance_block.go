@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Statuses of a maintenance block.
+const (
+	BlockScheduled = "scheduled"
+	BlockActive    = "active"
+	BlockExpired   = "expired"
+	BlockEnded     = "ended"
+)
+
+// MaintenanceBlock takes a place out of service, e.g. while it is blocked by
+// snow or under repair, from From until Until or until the block is ended. A
+// block without Until lasts until it is ended.
+type MaintenanceBlock struct {
+	BlockID     string     `bson:"_id" json:"block_id" example:"5d0c1a0e-3f7e-4c2b-9a55-6a1f0e2b7c44"`
+	PlaceNumber int        `bson:"place_number" json:"place_number" example:"7"`
+	Reason      string     `bson:"reason" json:"reason" example:"Уборка снега"`
+	From        time.Time  `bson:"from" json:"from" example:"2024-01-01T08:00:00Z"`
+	Until       *time.Time `bson:"until,omitempty" json:"until,omitempty" example:"2024-01-01T12:00:00Z"`
+	CreatedBy   string     `bson:"created_by,omitempty" json:"created_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at" example:"2024-01-01T07:55:00Z"`
+	EndedAt     *time.Time `bson:"ended_at,omitempty" json:"ended_at,omitempty" example:"2024-01-01T10:30:00Z"`
+	EndedBy     string     `bson:"ended_by,omitempty" json:"ended_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	Status      string     `bson:"-" json:"status" example:"active" enums:"scheduled,active,expired,ended"`
+}
+
+func (b MaintenanceBlock) CollectionName() string {
+	return "maintenance_blocks"
+}
+
+// StatusAt reports whether the block is ended, scheduled, active or expired
+// at now. Blocks expire by themselves once Until has passed.
+func (b MaintenanceBlock) StatusAt(now time.Time) string {
+	switch {
+	case b.EndedAt != nil:
+		return BlockEnded
+	case now.Before(b.From):
+		return BlockScheduled
+	case b.Until != nil && !now.Before(*b.Until):
+		return BlockExpired
+	}
+	return BlockActive
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ParkingReceipt is the billing summary of a parking session. It is computed
+// on request and not stored. PermitMinutes is the part of the session covered
+// by its permit, which is not billed.
+type ParkingReceipt struct {
+	LogID           string      `json:"log_id" example:"0b6b1b9e-2a55-4d8e-9c36-1f9a5f1f5d3e"`
+	PlaceNumber     int         `json:"place_number" example:"7"`
+	FirstName       string      `json:"first_name" example:"Иван"`
+	LastName        string      `json:"last_name" example:"Иванов"`
+	CarMake         string      `json:"car_make" example:"Toyota"`
+	LicensePlate    string      `json:"license_plate" example:"А123БВ777"`
+	StartedAt       time.Time   `json:"started_at" example:"2024-01-01T12:00:00Z"`
+	EndedAt         time.Time   `json:"ended_at" example:"2024-01-01T14:00:00Z"`
+	IsFinal         bool        `json:"is_final" example:"true"`
+	DurationMinutes int         `json:"duration_minutes" example:"120"`
+	BilledHours     int         `json:"billed_hours" example:"2"`
+	HourlyRate      int         `json:"hourly_rate" example:"100"`
+	Amount          int         `json:"amount" example:"200"`
+	PermitID        string      `json:"permit_id,omitempty" example:"9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"`
+	PermitMinutes   int         `json:"permit_minutes,omitempty" example:"90"`
+	Moves           []PlaceMove `json:"moves,omitempty"`
+}
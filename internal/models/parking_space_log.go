@@ -1,6 +1,7 @@
 package models
 
 import (
+	"log/slog"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,8 +18,36 @@ type ParkingSpaceLog struct {
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at" example:"2024-01-01T12:00:00Z"`
 	IsActive     bool               `bson:"is_active" json:"is_active" example:"true"`
 	FreeUpTime   *time.Time         `bson:"free_up_time,omitempty" json:"free_up_time,omitempty" example:"2024-01-01T14:00:00Z"`
+	Moves        []PlaceMove        `bson:"moves,omitempty" json:"moves,omitempty"`
+	OverstayedAt *time.Time         `bson:"overstayed_at,omitempty" json:"overstayed_at,omitempty" example:"2024-01-04T12:00:00Z"`
+	OutOfRangeAt *time.Time         `bson:"out_of_range_at,omitempty" json:"out_of_range_at,omitempty" example:"2024-01-02T09:00:00Z"`
+	CreatedBy    string             `bson:"created_by,omitempty" json:"created_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	VehicleID    string             `bson:"vehicle_id,omitempty" json:"vehicle_id,omitempty" example:"c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"`
+	DriverID     string             `bson:"driver_id,omitempty" json:"driver_id,omitempty" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
+	PermitID     string             `bson:"permit_id,omitempty" json:"permit_id,omitempty" example:"9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"`
+	Version      int64              `bson:"version" json:"version" example:"1"`
+}
+
+type PlaceMove struct {
+	FromPlace int       `bson:"from_place" json:"from_place" example:"1"`
+	ToPlace   int       `bson:"to_place" json:"to_place" example:"7"`
+	MovedAt   time.Time `bson:"moved_at" json:"moved_at" example:"2024-01-01T13:00:00Z"`
 }
 
 func (p ParkingSpaceLog) CollectionName() string {
 	return "parking_space_logs"
 }
+
+// LogValue keeps logged sessions compact. Personal data is still listed so
+// that the logger can redact it by key.
+func (p ParkingSpaceLog) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("log_id", p.LogID),
+		slog.Int("place_number", p.PlaceNumber),
+		slog.String("first_name", p.FirstName),
+		slog.String("last_name", p.LastName),
+		slog.String("license_plate", p.LicensePlate),
+		slog.Bool("is_active", p.IsActive),
+		slog.Int64("version", p.Version),
+	)
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Statuses of a permit.
+const (
+	PermitScheduled = "scheduled"
+	PermitActive    = "active"
+	PermitExpired   = "expired"
+	PermitRevoked   = "revoked"
+)
+
+// Permit entitles a registered vehicle, or every vehicle of a registered
+// driver, to park on the lot from ValidFrom until ValidUntil without paying
+// the tariff, in the permit zones listed in Zones, or in all of them if it is
+// empty. A permit with PlaceNumber reserves that place for its holder.
+type Permit struct {
+	PermitID       string     `bson:"_id" json:"permit_id" example:"9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"`
+	LotID          string     `bson:"lot_id" json:"lot_id" example:"main"`
+	VehicleID      string     `bson:"vehicle_id,omitempty" json:"vehicle_id,omitempty" example:"c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"`
+	DriverID       string     `bson:"driver_id,omitempty" json:"driver_id,omitempty" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
+	ValidFrom      time.Time  `bson:"valid_from" json:"valid_from" example:"2024-01-01T00:00:00Z"`
+	ValidUntil     time.Time  `bson:"valid_until" json:"valid_until" example:"2024-02-01T00:00:00Z"`
+	Zones          []string   `bson:"zones,omitempty" json:"zones,omitempty" example:"A"`
+	PlaceNumber    *int       `bson:"place_number,omitempty" json:"place_number,omitempty" example:"7"`
+	CreatedBy      string     `bson:"created_by,omitempty" json:"created_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	CreatedAt      time.Time  `bson:"created_at" json:"created_at" example:"2023-12-28T09:00:00Z"`
+	RevokedAt      *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty" example:"2024-01-15T18:00:00Z"`
+	RevokedBy      string     `bson:"revoked_by,omitempty" json:"revoked_by,omitempty" example:"key:3f2a9c1b7d4e"`
+	ReminderSentAt *time.Time `bson:"reminder_sent_at,omitempty" json:"reminder_sent_at,omitempty" example:"2024-01-25T00:00:00Z"`
+	Status         string     `bson:"-" json:"status" example:"active" enums:"scheduled,active,expired,revoked"`
+}
+
+func (p Permit) CollectionName() string {
+	return "permits"
+}
+
+// StatusAt reports whether the permit is revoked, scheduled, active or
+// expired at now.
+func (p Permit) StatusAt(now time.Time) string {
+	switch {
+	case p.RevokedAt != nil:
+		return PermitRevoked
+	case now.Before(p.ValidFrom):
+		return PermitScheduled
+	case !now.Before(p.ValidUntil):
+		return PermitExpired
+	}
+	return PermitActive
+}
+
+// AllowsZone reports whether the permit grants access to the permit zone
+// zone.
+func (p Permit) AllowsZone(zone string) bool {
+	return len(p.Zones) == 0 || slices.Contains(p.Zones, zone)
+}
+
+// PlaceLock is a short lease on a place of a lot, held while a permit
+// reserving the place is checked against the other permits and written, so
+// that two permits cannot reserve it for overlapping periods. A lease left
+// behind by a crashed instance is taken over once it expires.
+type PlaceLock struct {
+	ID        string    `bson:"_id"`
+	Holder    string    `bson:"holder"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func (l PlaceLock) CollectionName() string {
+	return "place_locks"
+}
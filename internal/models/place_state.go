@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PlaceState keeps what the lot map shows about a place beyond its current
+// session, so that it need not be derived from the whole session history.
+type PlaceState struct {
+	PlaceNumber   int       `bson:"_id"`
+	LastVacatedAt time.Time `bson:"last_vacated_at"`
+}
+
+func (s PlaceState) CollectionName() string {
+	return "place_states"
+}
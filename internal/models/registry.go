@@ -0,0 +1,67 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Size classes of a vehicle.
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Driver is a registered person who parks one or more vehicles.
+type Driver struct {
+	DriverID  string    `bson:"_id" json:"driver_id" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
+	FirstName string    `bson:"first_name" json:"first_name" example:"Иван"`
+	LastName  string    `bson:"last_name" json:"last_name" example:"Иванов"`
+	Phone     string    `bson:"phone,omitempty" json:"phone,omitempty" example:"+79991234567"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at" example:"2024-01-01T12:00:00Z"`
+}
+
+func (d Driver) CollectionName() string {
+	return "drivers"
+}
+
+// Vehicle is a registered car owned by a driver. LicensePlate is stored
+// normalised, see NormalizeLicensePlate, and is unique.
+type Vehicle struct {
+	VehicleID    string    `bson:"_id" json:"vehicle_id" example:"c3a9e7d1-5b2f-4e8a-9d6c-1f0b2a4c6e8d"`
+	DriverID     string    `bson:"driver_id" json:"driver_id" example:"8e1f5c2a-7b3d-4a9e-b6c1-2d4f6a8b0c1e"`
+	LicensePlate string    `bson:"license_plate" json:"license_plate" example:"А123БВ777"`
+	Make         string    `bson:"make" json:"make" example:"Toyota"`
+	Model        string    `bson:"model,omitempty" json:"model,omitempty" example:"Camry"`
+	Colour       string    `bson:"colour,omitempty" json:"colour,omitempty" example:"белый"`
+	SizeClass    string    `bson:"size_class,omitempty" json:"size_class,omitempty" example:"medium" enums:"small,medium,large"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt    time.Time `bson:"updated_at" json:"updated_at" example:"2024-01-01T12:00:00Z"`
+}
+
+func (v Vehicle) CollectionName() string {
+	return "vehicles"
+}
+
+// plateLookalikes maps the Latin letters that Russian plates use in their
+// Cyrillic form to those Cyrillic letters.
+var plateLookalikes = strings.NewReplacer(
+	"A", "А", "B", "В", "E", "Е", "K", "К", "M", "М", "H", "Н",
+	"O", "О", "P", "Р", "C", "С", "T", "Т", "Y", "У", "X", "Х",
+)
+
+// NormalizeLicensePlate brings a plate into the form it is stored and
+// compared in: upper case, without spaces and dashes, and with Latin letters
+// that look like Cyrillic ones replaced by them, so that "a123bc 777" and
+// "А123ВС777" are the same plate.
+func NormalizeLicensePlate(plate string) string {
+	plate = strings.ToUpper(plate)
+	plate = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, plate)
+	return plateLookalikes.Replace(plate)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestNormalizeLicensePlate(t *testing.T) {
+	tests := []struct {
+		name  string
+		plate string
+		want  string
+	}{
+		{name: "already normalized", plate: "А123ВС777", want: "А123ВС777"},
+		{name: "lower-case Cyrillic", plate: "а123вс777", want: "А123ВС777"},
+		{name: "Latin lookalikes", plate: "A123BC777", want: "А123ВС777"},
+		{name: "lower-case Latin with a space", plate: "a123bc 777", want: "А123ВС777"},
+		{name: "dashes and tabs", plate: "А-123-ВС\t777", want: "А123ВС777"},
+		{name: "mixed scripts", plate: "A123ВC77", want: "А123ВС77"},
+		{name: "Latin letters without a lookalike", plate: "ab-1234 z", want: "АВ1234Z"},
+		{name: "empty", plate: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeLicensePlate(tt.plate); got != tt.want {
+				t.Errorf("NormalizeLicensePlate(%q) = %q, want %q", tt.plate, got, tt.want)
+			}
+		})
+	}
+}
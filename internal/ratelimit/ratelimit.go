@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket budget: Burst requests at once, refilled at Rate
+// requests per second. A zero Rate disables the limit.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute returns a limit of requests per minute with the given burst.
+func PerMinute(requests int, burst int) Limit {
+	return Limit{Rate: float64(requests) / 60, Burst: burst}
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result describes the state of a bucket after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, if this one
+	// was not.
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// cleanupPeriod is how often buckets that have refilled completely are
+// dropped, so that clients seen once do not keep memory.
+const cleanupPeriod = time.Minute
+
+// evictionSample is how many buckets are looked at to find one to evict when
+// the limiter is full.
+const evictionSample = 8
+
+// Limiter keeps one token bucket per key in memory. Limits are therefore per
+// instance; with several replicas each enforces its own budget. At most
+// maxBuckets keys are tracked: when a new key arrives at a full limiter, the
+// refilled buckets are dropped, and if that is not enough, the least recently
+// used of a few buckets is evicted.
+type Limiter struct {
+	limit      Limit
+	maxBuckets int
+	now        func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+// New returns a limiter tracking at most maxBuckets keys, or any number of
+// them if maxBuckets is zero.
+func New(limit Limit, maxBuckets int) *Limiter {
+	return &Limiter{limit: limit, maxBuckets: maxBuckets, now: time.Now, buckets: make(map[string]*bucket), lastCleanup: time.Now()}
+}
+
+// Allow takes a token from the bucket of key if one is available. A disabled
+// limit allows everything.
+func (l *Limiter) Allow(key string) Result {
+	if !l.limit.Enabled() {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) >= cleanupPeriod {
+		l.cleanup(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if l.maxBuckets > 0 && len(l.buckets) >= l.maxBuckets {
+			l.cleanup(now)
+			if len(l.buckets) >= l.maxBuckets {
+				l.evict()
+			}
+		}
+		b = &bucket{tokens: float64(l.limit.Burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*l.limit.Rate)
+	b.updated = now
+
+	result := Result{Limit: l.limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.timeToFill(1 - b.tokens)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = l.timeToFill(float64(l.limit.Burst) - b.tokens)
+	return result
+}
+
+func (l *Limiter) timeToFill(tokens float64) time.Duration {
+	return time.Duration(tokens / l.limit.Rate * float64(time.Second))
+}
+
+func (l *Limiter) cleanup(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+// evict drops the least recently used of a sample of buckets. Map iteration
+// order is random, so the sample is too.
+func (l *Limiter) evict() {
+	var oldestKey string
+	var oldest time.Time
+	sampled := 0
+	for key, b := range l.buckets {
+		if sampled == 0 || b.updated.Before(oldest) {
+			oldestKey, oldest = key, b.updated
+		}
+		sampled++
+		if sampled == evictionSample {
+			break
+		}
+	}
+	delete(l.buckets, oldestKey)
+}
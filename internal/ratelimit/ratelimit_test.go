@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a manually advanced time source.
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newTestLimiter(limit Limit, maxBuckets int) (*Limiter, *clock) {
+	c := &clock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := New(limit, maxBuckets)
+	l.now = c.Now
+	l.lastCleanup = c.now
+	return l, c
+}
+
+func TestAllow(t *testing.T) {
+	type step struct {
+		wait          time.Duration
+		wantAllowed   bool
+		wantRemaining int
+	}
+	tests := []struct {
+		name  string
+		limit Limit
+		steps []step
+	}{
+		{
+			name:  "disabled limit",
+			limit: Limit{},
+			steps: []step{{wantAllowed: true}, {wantAllowed: true}},
+		},
+		{
+			name:  "burst then rejected",
+			limit: PerMinute(60, 2),
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wantAllowed: false, wantRemaining: 0},
+			},
+		},
+		{
+			name:  "refilled at the rate",
+			limit: PerMinute(60, 2),
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1},
+				{wantAllowed: true, wantRemaining: 0},
+				{wait: time.Second, wantAllowed: true, wantRemaining: 0},
+				{wait: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0},
+			},
+		},
+		{
+			name:  "refill capped at the burst",
+			limit: PerMinute(60, 2),
+			steps: []step{
+				{wantAllowed: true, wantRemaining: 1},
+				{wait: time.Hour, wantAllowed: true, wantRemaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, c := newTestLimiter(tt.limit, 0)
+			for i, step := range tt.steps {
+				c.now = c.now.Add(step.wait)
+				result := l.Allow("key")
+				if result.Allowed != step.wantAllowed {
+					t.Errorf("step %d: Allowed = %v, want %v", i, result.Allowed, step.wantAllowed)
+				}
+				if result.Remaining != step.wantRemaining {
+					t.Errorf("step %d: Remaining = %d, want %d", i, result.Remaining, step.wantRemaining)
+				}
+			}
+		})
+	}
+}
+
+func TestAllowRetryAfter(t *testing.T) {
+	l, _ := newTestLimiter(PerMinute(30, 1), 0)
+	l.Allow("key")
+
+	result := l.Allow("key")
+	if result.Allowed {
+		t.Fatal("Allowed = true, want false")
+	}
+	if result.RetryAfter != 2*time.Second {
+		t.Errorf("RetryAfter = %v, want 2s", result.RetryAfter)
+	}
+	if result.Reset != 2*time.Second {
+		t.Errorf("Reset = %v, want 2s", result.Reset)
+	}
+}
+
+func TestAllowSeparateKeys(t *testing.T) {
+	l, _ := newTestLimiter(PerMinute(60, 1), 0)
+	if !l.Allow("a").Allowed {
+		t.Error("first request of a rejected")
+	}
+	if !l.Allow("b").Allowed {
+		t.Error("first request of b rejected")
+	}
+	if l.Allow("a").Allowed {
+		t.Error("second request of a allowed")
+	}
+}
+
+func TestBucketEviction(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBuckets  int
+		keys        int
+		wait        time.Duration
+		wantBuckets int
+	}{
+		{name: "unbounded", maxBuckets: 0, keys: 50, wantBuckets: 50},
+		{name: "bounded", maxBuckets: 10, keys: 50, wantBuckets: 10},
+		{name: "refilled buckets dropped first", maxBuckets: 10, keys: 10, wait: time.Minute, wantBuckets: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, c := newTestLimiter(PerMinute(60, 5), tt.maxBuckets)
+			for i := 0; i < tt.keys; i++ {
+				l.Allow(string(rune('A' + i)))
+				c.now = c.now.Add(time.Millisecond)
+			}
+			c.now = c.now.Add(tt.wait)
+			if tt.wait > 0 {
+				l.Allow("new")
+			}
+			if got := len(l.buckets); got != tt.wantBuckets {
+				t.Errorf("buckets = %d, want %d", got, tt.wantBuckets)
+			}
+		})
+	}
+}
+
+func TestBucketEvictionKeepsRecentKeys(t *testing.T) {
+	l, c := newTestLimiter(PerMinute(60, 1), 2)
+	l.Allow("old")
+	c.now = c.now.Add(time.Millisecond)
+	l.Allow("recent")
+	c.now = c.now.Add(time.Millisecond)
+
+	// With two buckets the sample covers both, so the older one goes.
+	l.Allow("new")
+	if _, ok := l.buckets["old"]; ok {
+		t.Error("least recently used bucket kept")
+	}
+	if l.Allow("recent").Allowed {
+		t.Error("recently used bucket was reset")
+	}
+}
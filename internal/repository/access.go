@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func (r *Repository) GetAccessPolicy(ctx context.Context, lotID string) (*models.AccessPolicy, error) {
+	collection := r.db.Collection(models.AccessPolicy{}.CollectionName())
+
+	var policy models.AccessPolicy
+	err := collection.FindOne(ctx, bson.M{"_id": lotID}).Decode(&policy)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (r *Repository) SetAccessPolicy(ctx context.Context, policy *models.AccessPolicy) error {
+	collection := r.db.Collection(policy.CollectionName())
+	_, err := collection.ReplaceOne(ctx, bson.M{"_id": policy.LotID}, policy, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *Repository) AddPlateListEntry(ctx context.Context, entry *models.PlateListEntry) error {
+	collection := r.db.Collection(entry.CollectionName())
+	_, err := collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// GetPlateListEntry looks a normalised plate up on a list of the lot.
+func (r *Repository) GetPlateListEntry(ctx context.Context, lotID, list, licensePlate string) (*models.PlateListEntry, error) {
+	collection := r.db.Collection(models.PlateListEntry{}.CollectionName())
+
+	var entry models.PlateListEntry
+	err := collection.FindOne(ctx, bson.M{"lot_id": lotID, "list": list, "license_plate": licensePlate}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetPlateListEntries returns a list of the lot ordered by plate.
+func (r *Repository) GetPlateListEntries(ctx context.Context, lotID, list string) ([]models.PlateListEntry, error) {
+	collection := r.db.Collection(models.PlateListEntry{}.CollectionName())
+	opts := options.Find().SetSort(bson.D{{Key: "license_plate", Value: 1}})
+	cursor, err := collection.Find(ctx, bson.M{"lot_id": lotID, "list": list}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.PlateListEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DeletePlateListEntry takes a normalised plate off a list of the lot. It
+// reports false if the plate was not on it.
+func (r *Repository) DeletePlateListEntry(ctx context.Context, lotID, list, licensePlate string) (bool, error) {
+	collection := r.db.Collection(models.PlateListEntry{}.CollectionName())
+	result, err := collection.DeleteOne(ctx, bson.M{"lot_id": lotID, "list": list, "license_plate": licensePlate})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *Repository) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	collection := r.db.Collection(entry.CollectionName())
+	_, err := collection.InsertOne(ctx, entry)
+	return err
+}
+
+// CollapseAuditEntry counts entry into the latest matching entry recorded
+// since since: one for the same lot, action, actor, list, plate and reason.
+// It reports false if there is none, so that entry has to be added.
+func (r *Repository) CollapseAuditEntry(ctx context.Context, entry *models.AuditEntry, since time.Time) (bool, error) {
+	collection := r.db.Collection(entry.CollectionName())
+	filter := bson.M{
+		"lot_id":        entry.LotID,
+		"action":        entry.Action,
+		"license_plate": entry.LicensePlate,
+		"at":            bson.M{"$gte": since},
+		"actor":         optional(entry.Actor),
+		"list":          optional(entry.List),
+		"reason":        optional(entry.Reason),
+	}
+	update := bson.M{
+		"$inc": bson.M{"count": 1},
+		"$set": bson.M{"last_at": entry.At, "expires_at": entry.ExpiresAt},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// optional matches an omitempty field holding value, or missing if value is
+// empty.
+func optional(value string) interface{} {
+	if value == "" {
+		return bson.M{"$exists": false}
+	}
+	return value
+}
+
+// GetAuditEntries returns the latest limit entries of the audit trail of the
+// lot, latest first.
+func (r *Repository) GetAuditEntries(ctx context.Context, lotID string, limit int64) ([]models.AuditEntry, error) {
+	collection := r.db.Collection(models.AuditEntry{}.CollectionName())
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}, {Key: "_id", Value: 1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"lot_id": lotID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []models.AuditEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+// CreateIdempotencyKey reserves the key for a request in progress. It reports
+// false if an unexpired record for the key already exists. The TTL monitor
+// only runs once a minute, so expired records are removed here as well.
+func (r *Repository) CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	collection := r.db.Collection(key.CollectionName())
+
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": key.Key, "expires_at": bson.M{"$lte": time.Now().UTC()}})
+	if err != nil {
+		return false, err
+	}
+	if result.DeletedCount > 0 {
+		r.logger.DebugContext(ctx, "Removed expired idempotency key", "key", key.Key)
+	}
+
+	_, err = collection.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Repository) GetIdempotencyKey(ctx context.Context, key string) (*models.IdempotencyKey, error) {
+	collection := r.db.Collection(models.IdempotencyKey{}.CollectionName())
+	filter := bson.M{"_id": key}
+
+	var idempotencyKey models.IdempotencyKey
+	err := collection.FindOne(ctx, filter).Decode(&idempotencyKey)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &idempotencyKey, nil
+}
+
+func (r *Repository) CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	collection := r.db.Collection(key.CollectionName())
+	filter := bson.M{"_id": key.Key}
+	update := bson.M{"$set": bson.M{
+		"completed":     true,
+		"status_code":   key.StatusCode,
+		"content_type":  key.ContentType,
+		"location":      key.Location,
+		"response_body": key.ResponseBody,
+	}}
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *Repository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	collection := r.db.Collection(models.IdempotencyKey{}.CollectionName())
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
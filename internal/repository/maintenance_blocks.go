@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func (r *Repository) AddMaintenanceBlock(ctx context.Context, block *models.MaintenanceBlock) error {
+	collection := r.db.Collection(block.CollectionName())
+	_, err := collection.InsertOne(ctx, block)
+	return err
+}
+
+func (r *Repository) GetMaintenanceBlock(ctx context.Context, blockID string) (*models.MaintenanceBlock, error) {
+	collection := r.db.Collection(models.MaintenanceBlock{}.CollectionName())
+
+	var block models.MaintenanceBlock
+	err := collection.FindOne(ctx, bson.M{"_id": blockID}).Decode(&block)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetMaintenanceBlocks returns the blocks, latest first. With unfinished set,
+// it leaves out the blocks that have ended or expired by now.
+func (r *Repository) GetMaintenanceBlocks(ctx context.Context, unfinished bool, now time.Time) ([]models.MaintenanceBlock, error) {
+	filter := bson.M{}
+	if unfinished {
+		filter = bson.M{
+			"ended_at": bson.M{"$exists": false},
+			"$or":      bson.A{bson.M{"until": bson.M{"$exists": false}}, bson.M{"until": bson.M{"$gt": now}}},
+		}
+	}
+	return r.findMaintenanceBlocks(ctx, filter, options.Find().SetSort(bson.D{{Key: "from", Value: -1}}))
+}
+
+// GetActiveMaintenanceBlocks returns the blocks in effect at now, earliest
+// first.
+func (r *Repository) GetActiveMaintenanceBlocks(ctx context.Context, now time.Time) ([]models.MaintenanceBlock, error) {
+	filter := bson.M{
+		"ended_at": bson.M{"$exists": false},
+		"from":     bson.M{"$lte": now},
+		"$or":      bson.A{bson.M{"until": bson.M{"$exists": false}}, bson.M{"until": bson.M{"$gt": now}}},
+	}
+	return r.findMaintenanceBlocks(ctx, filter, options.Find().SetSort(bson.D{{Key: "from", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+func (r *Repository) findMaintenanceBlocks(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.MaintenanceBlock, error) {
+	collection := r.db.Collection(models.MaintenanceBlock{}.CollectionName())
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blocks []models.MaintenanceBlock
+	if err = cursor.All(ctx, &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// EndMaintenanceBlock ends a block that has neither ended nor expired by
+// now. It reports false if there is no such block.
+func (r *Repository) EndMaintenanceBlock(ctx context.Context, blockID, endedBy string, now time.Time) (bool, error) {
+	collection := r.db.Collection(models.MaintenanceBlock{}.CollectionName())
+	filter := bson.M{
+		"_id":      blockID,
+		"ended_at": bson.M{"$exists": false},
+		"$or":      bson.A{bson.M{"until": bson.M{"$exists": false}}, bson.M{"until": bson.M{"$gt": now}}},
+	}
+	update := bson.M{"$set": bson.M{"ended_at": now, "ended_by": endedBy}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func (r *Repository) AddPermit(ctx context.Context, permit *models.Permit) error {
+	collection := r.db.Collection(permit.CollectionName())
+	_, err := collection.InsertOne(ctx, permit)
+	return err
+}
+
+func (r *Repository) GetPermit(ctx context.Context, permitID string) (*models.Permit, error) {
+	collection := r.db.Collection(models.Permit{}.CollectionName())
+
+	var permit models.Permit
+	err := collection.FindOne(ctx, bson.M{"_id": permitID}).Decode(&permit)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &permit, nil
+}
+
+// GetPermits returns the permits of the lot, latest first. With unfinished
+// set, it leaves out the permits that have been revoked or expired by now.
+func (r *Repository) GetPermits(ctx context.Context, lotID string, unfinished bool, now time.Time) ([]models.Permit, error) {
+	filter := bson.M{"lot_id": lotID}
+	if unfinished {
+		filter["revoked_at"] = bson.M{"$exists": false}
+		filter["valid_until"] = bson.M{"$gt": now}
+	}
+	return r.findPermits(ctx, filter, options.Find().SetSort(bson.D{{Key: "valid_from", Value: -1}, {Key: "_id", Value: 1}}))
+}
+
+// GetActivePermits returns the permits of the lot in effect at now, earliest
+// first. Only the permits of vehicleID or driverID are returned if either is
+// set, and only those with a dedicated place if withPlace is set.
+func (r *Repository) GetActivePermits(ctx context.Context, lotID, vehicleID, driverID string, withPlace bool, now time.Time) ([]models.Permit, error) {
+	filter := bson.M{
+		"lot_id":      lotID,
+		"revoked_at":  bson.M{"$exists": false},
+		"valid_from":  bson.M{"$lte": now},
+		"valid_until": bson.M{"$gt": now},
+	}
+	var holders bson.A
+	if vehicleID != "" {
+		holders = append(holders, bson.M{"vehicle_id": vehicleID})
+	}
+	if driverID != "" {
+		holders = append(holders, bson.M{"driver_id": driverID})
+	}
+	if holders != nil {
+		filter["$or"] = holders
+	}
+	if withPlace {
+		filter["place_number"] = bson.M{"$exists": true}
+	}
+	return r.findPermits(ctx, filter, options.Find().SetSort(bson.D{{Key: "valid_from", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+// GetPermitsForPlace returns the unrevoked permits of the lot that reserve
+// placeNumber at some time between from and until.
+func (r *Repository) GetPermitsForPlace(ctx context.Context, lotID string, placeNumber int, from, until time.Time) ([]models.Permit, error) {
+	filter := bson.M{
+		"lot_id":       lotID,
+		"place_number": placeNumber,
+		"revoked_at":   bson.M{"$exists": false},
+		"valid_from":   bson.M{"$lt": until},
+		"valid_until":  bson.M{"$gt": from},
+	}
+	return r.findPermits(ctx, filter, nil)
+}
+
+// GetExpiringPermits returns the unrevoked permits of the lot that expire
+// after now but no later than before, soonest first.
+func (r *Repository) GetExpiringPermits(ctx context.Context, lotID string, now, before time.Time) ([]models.Permit, error) {
+	filter := bson.M{
+		"lot_id":      lotID,
+		"revoked_at":  bson.M{"$exists": false},
+		"valid_until": bson.M{"$gt": now, "$lte": before},
+	}
+	return r.findPermits(ctx, filter, options.Find().SetSort(bson.D{{Key: "valid_until", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+func (r *Repository) findPermits(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Permit, error) {
+	collection := r.db.Collection(models.Permit{}.CollectionName())
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var permits []models.Permit
+	if err = cursor.All(ctx, &permits); err != nil {
+		return nil, err
+	}
+	return permits, nil
+}
+
+// RevokePermit revokes a permit that has neither been revoked nor expired by
+// now. It reports false if there is no such permit.
+func (r *Repository) RevokePermit(ctx context.Context, permitID, revokedBy string, now time.Time) (bool, error) {
+	collection := r.db.Collection(models.Permit{}.CollectionName())
+	filter := bson.M{
+		"_id":         permitID,
+		"revoked_at":  bson.M{"$exists": false},
+		"valid_until": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": now, "revoked_by": revokedBy}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// MarkPermitReminded records that the expiry reminder of a permit was sent.
+// It reports false if it had already been recorded, e.g. by another
+// instance.
+func (r *Repository) MarkPermitReminded(ctx context.Context, permitID string, now time.Time) (bool, error) {
+	collection := r.db.Collection(models.Permit{}.CollectionName())
+	filter := bson.M{"_id": permitID, "reminder_sent_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"reminder_sent_at": now}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// LockPlace takes the lease on placeNumber of the lot for holder until
+// expiresAt. It reports false while another holder has a lease that has not
+// expired by now.
+func (r *Repository) LockPlace(ctx context.Context, lotID string, placeNumber int, holder string, now, expiresAt time.Time) (bool, error) {
+	collection := r.db.Collection(models.PlaceLock{}.CollectionName())
+	// A lease in effect does not match, so the upsert tries to insert a
+	// second lock with the same ID and fails.
+	filter := bson.M{"_id": placeLockID(lotID, placeNumber), "expires_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"holder": holder, "expires_at": expiresAt}}
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnlockPlace releases the lease of holder on placeNumber of the lot, if it
+// still has it.
+func (r *Repository) UnlockPlace(ctx context.Context, lotID string, placeNumber int, holder string) error {
+	collection := r.db.Collection(models.PlaceLock{}.CollectionName())
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": placeLockID(lotID, placeNumber), "holder": holder})
+	return err
+}
+
+func placeLockID(lotID string, placeNumber int) string {
+	return fmt.Sprintf("%s/%d", lotID, placeNumber)
+}
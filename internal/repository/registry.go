@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+// ErrDuplicate is returned when a document would violate a unique index.
+var ErrDuplicate = errors.New("duplicate document")
+
+func (r *Repository) AddDriver(ctx context.Context, driver *models.Driver) error {
+	collection := r.db.Collection(driver.CollectionName())
+	_, err := collection.InsertOne(ctx, driver)
+	return err
+}
+
+func (r *Repository) GetDriver(ctx context.Context, driverID string) (*models.Driver, error) {
+	collection := r.db.Collection(models.Driver{}.CollectionName())
+
+	var driver models.Driver
+	err := collection.FindOne(ctx, bson.M{"_id": driverID}).Decode(&driver)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// GetDrivers returns the drivers ordered by name.
+func (r *Repository) GetDrivers(ctx context.Context) ([]models.Driver, error) {
+	collection := r.db.Collection(models.Driver{}.CollectionName())
+	opts := options.Find().
+		SetSort(bson.D{{Key: "last_name", Value: 1}, {Key: "first_name", Value: 1}}).
+		SetCollation(NameCollation)
+
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var drivers []models.Driver
+	if err = cursor.All(ctx, &drivers); err != nil {
+		return nil, err
+	}
+	return drivers, nil
+}
+
+// ReplaceDriver stores driver over the existing one. It reports false if
+// there is no driver with its ID.
+func (r *Repository) ReplaceDriver(ctx context.Context, driver *models.Driver) (bool, error) {
+	collection := r.db.Collection(driver.CollectionName())
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": driver.DriverID}, driver)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *Repository) DeleteDriver(ctx context.Context, driverID string) (bool, error) {
+	collection := r.db.Collection(models.Driver{}.CollectionName())
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": driverID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *Repository) AddVehicle(ctx context.Context, vehicle *models.Vehicle) error {
+	collection := r.db.Collection(vehicle.CollectionName())
+	_, err := collection.InsertOne(ctx, vehicle)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *Repository) GetVehicle(ctx context.Context, vehicleID string) (*models.Vehicle, error) {
+	return r.findVehicle(ctx, bson.M{"_id": vehicleID})
+}
+
+// GetVehicleByLicensePlate looks a vehicle up by its normalised plate.
+func (r *Repository) GetVehicleByLicensePlate(ctx context.Context, licensePlate string) (*models.Vehicle, error) {
+	return r.findVehicle(ctx, bson.M{"license_plate": licensePlate})
+}
+
+func (r *Repository) findVehicle(ctx context.Context, filter bson.M) (*models.Vehicle, error) {
+	collection := r.db.Collection(models.Vehicle{}.CollectionName())
+
+	var vehicle models.Vehicle
+	err := collection.FindOne(ctx, filter).Decode(&vehicle)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &vehicle, nil
+}
+
+// GetVehicles returns the vehicles ordered by plate, only those of driverID
+// if it is set.
+func (r *Repository) GetVehicles(ctx context.Context, driverID string) ([]models.Vehicle, error) {
+	collection := r.db.Collection(models.Vehicle{}.CollectionName())
+	filter := bson.M{}
+	if driverID != "" {
+		filter["driver_id"] = driverID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "license_plate", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var vehicles []models.Vehicle
+	if err = cursor.All(ctx, &vehicles); err != nil {
+		return nil, err
+	}
+	return vehicles, nil
+}
+
+func (r *Repository) CountVehiclesOfDriver(ctx context.Context, driverID string) (int64, error) {
+	collection := r.db.Collection(models.Vehicle{}.CollectionName())
+	return collection.CountDocuments(ctx, bson.M{"driver_id": driverID})
+}
+
+// ReplaceVehicle stores vehicle over the existing one. It reports false if
+// there is no vehicle with its ID.
+func (r *Repository) ReplaceVehicle(ctx context.Context, vehicle *models.Vehicle) (bool, error) {
+	collection := r.db.Collection(vehicle.CollectionName())
+	result, err := collection.ReplaceOne(ctx, bson.M{"_id": vehicle.VehicleID}, vehicle)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, ErrDuplicate
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *Repository) DeleteVehicle(ctx context.Context, vehicleID string) (bool, error) {
+	collection := r.db.Collection(models.Vehicle{}.CollectionName())
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": vehicleID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -2,30 +2,140 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/amend-parking-backend/internal/database"
 	"github.com/amend-parking-backend/internal/models"
 )
 
-type Repository struct{}
+// ErrVersionConflict is returned by conditional updates when the stored
+// document no longer has the version the caller read.
+var ErrVersionConflict = errors.New("document version conflict")
 
-func NewRepository() *Repository {
-	return &Repository{}
+// NameCollation compares driver names case-insensitively. Queries by name
+// must use it to be served by the name index.
+var NameCollation = &options.Collation{Locale: "ru", Strength: 2}
+
+type Repository struct {
+	db     *mongo.Database
+	logger *slog.Logger
+}
+
+func NewRepository(db *mongo.Database, logger *slog.Logger) *Repository {
+	return &Repository{db: db, logger: logger}
 }
 
 func (r *Repository) GetCountOfOccupiedSpaces(ctx context.Context) (int64, error) {
-	collection := database.DB.Collection(models.ParkingSpaceLog{}.CollectionName())
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
 	filter := bson.M{"is_active": true}
 	count, err := collection.CountDocuments(ctx, filter)
 	return count, err
 }
 
+// GetOccupiedPlaceNumbers returns the places of all active sessions in one
+// aggregation, in ascending order.
+func (r *Repository) GetOccupiedPlaceNumbers(ctx context.Context) ([]int, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"is_active": true}}},
+		{{Key: "$sort", Value: bson.M{"place_number": 1}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "places": bson.M{"$push": "$place_number"}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Places []int `bson:"places"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result[0].Places, nil
+}
+
+// GetPlaceVacatedTimes returns when each place was last vacated, either by a
+// session that ended there or by a car moved away from it, as recorded by
+// RecordPlaceVacated.
+func (r *Repository) GetPlaceVacatedTimes(ctx context.Context) (map[int]time.Time, error) {
+	collection := r.db.Collection(models.PlaceState{}.CollectionName())
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var states []models.PlaceState
+	if err = cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+
+	vacated := make(map[int]time.Time, len(states))
+	for _, state := range states {
+		vacated[state.PlaceNumber] = state.LastVacatedAt
+	}
+	return vacated, nil
+}
+
+// RecordPlaceVacated records that placeNumber was vacated at at, unless it
+// is already known to have been vacated later.
+func (r *Repository) RecordPlaceVacated(ctx context.Context, placeNumber int, at time.Time) error {
+	collection := r.db.Collection(models.PlaceState{}.CollectionName())
+	update := bson.M{"$max": bson.M{"last_vacated_at": at}}
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": placeNumber}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// GetCountOfActiveParkingSpaceLogsCreatedBy counts the active sessions
+// started by a client.
+func (r *Repository) GetCountOfActiveParkingSpaceLogsCreatedBy(ctx context.Context, createdBy string) (int64, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{"is_active": true, "created_by": createdBy}
+	return collection.CountDocuments(ctx, filter)
+}
+
+// GetOldestActiveParkingSpaceLogIDsCreatedBy returns the IDs of the limit
+// oldest active sessions started by a client, in the order of their IDs.
+func (r *Repository) GetOldestActiveParkingSpaceLogIDsCreatedBy(ctx context.Context, createdBy string, limit int64) ([]primitive.ObjectID, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{"is_active": true, "created_by": createdBy}
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids, nil
+}
+
 func (r *Repository) GetOccupiedSpaces(ctx context.Context) ([]models.ParkingSpaceLog, error) {
-	collection := database.DB.Collection(models.ParkingSpaceLog{}.CollectionName())
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
 	filter := bson.M{"is_active": true}
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
@@ -42,7 +152,7 @@ func (r *Repository) GetOccupiedSpaces(ctx context.Context) ([]models.ParkingSpa
 }
 
 func (r *Repository) GetParkingSpaceLogByPlaceNumber(ctx context.Context, placeNumber int) (*models.ParkingSpaceLog, error) {
-	collection := database.DB.Collection(models.ParkingSpaceLog{}.CollectionName())
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
 	filter := bson.M{"place_number": placeNumber, "is_active": true}
 
 	var log models.ParkingSpaceLog
@@ -57,8 +167,11 @@ func (r *Repository) GetParkingSpaceLogByPlaceNumber(ctx context.Context, placeN
 }
 
 func (r *Repository) AddParkingSpaceLog(ctx context.Context, log *models.ParkingSpaceLog) error {
-	collection := database.DB.Collection(log.CollectionName())
+	collection := r.db.Collection(log.CollectionName())
 	result, err := collection.InsertOne(ctx, log)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
 	if err != nil {
 		return err
 	}
@@ -68,15 +181,24 @@ func (r *Repository) AddParkingSpaceLog(ctx context.Context, log *models.Parking
 	return nil
 }
 
+// DeleteParkingSpaceLog removes a session that was added but must not be
+// kept.
+func (r *Repository) DeleteParkingSpaceLog(ctx context.Context, id primitive.ObjectID) error {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
 func (r *Repository) GetParkingSpaceLogsByFirstNameAndLastName(ctx context.Context, firstName, lastName string) ([]models.ParkingSpaceLog, error) {
-	collection := database.DB.Collection(models.ParkingSpaceLog{}.CollectionName())
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
 	filter := bson.M{
-		"first_name": bson.M{"$regex": "^" + firstName + "$", "$options": "i"},
-		"last_name":  bson.M{"$regex": "^" + lastName + "$", "$options": "i"},
+		"first_name": firstName,
+		"last_name":  lastName,
 		"is_active":  true,
 	}
+	opts := options.Find().SetCollation(NameCollation)
 
-	cursor, err := collection.Find(ctx, filter)
+	cursor, err := collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -90,10 +212,207 @@ func (r *Repository) GetParkingSpaceLogsByFirstNameAndLastName(ctx context.Conte
 	return logs, nil
 }
 
+// UpdateParkingSpaceLog replaces the stored log only if it still has the
+// version of log, and increments the version on success.
 func (r *Repository) UpdateParkingSpaceLog(ctx context.Context, log *models.ParkingSpaceLog) error {
-	collection := database.DB.Collection(log.CollectionName())
-	filter := bson.M{"_id": log.ID}
+	collection := r.db.Collection(log.CollectionName())
+	filter := bson.M{"_id": log.ID, "version": versionFilter(log.Version)}
+
+	log.Version++
 	update := bson.M{"$set": log}
-	_, err := collection.UpdateOne(ctx, filter, update)
-	return err
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err == nil && result.MatchedCount == 0 {
+		r.logger.DebugContext(ctx, "Parking space log version conflict", "log_id", log.LogID, "version", log.Version-1)
+		err = ErrVersionConflict
+	}
+	if err != nil {
+		log.Version--
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetParkingSpaceLogByLogID(ctx context.Context, logID string) (*models.ParkingSpaceLog, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{"log_id": logID}
+
+	var log models.ParkingSpaceLog
+	err := collection.FindOne(ctx, filter).Decode(&log)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+// MoveParkingSpaceLog switches an active session from one place to another in a
+// single document update, so the old place is released and the new one taken
+// at the same time. Like UpdateParkingSpaceLog it only applies to the version
+// of log the caller read. If another active session holds the new place, the
+// unique index on active places makes it fail with ErrDuplicate. The overstay
+// mark is cleared, since the maximum stay depends on the place.
+func (r *Repository) MoveParkingSpaceLog(ctx context.Context, log *models.ParkingSpaceLog, move models.PlaceMove) error {
+	collection := r.db.Collection(log.CollectionName())
+	filter := bson.M{
+		"_id":          log.ID,
+		"version":      versionFilter(log.Version),
+		"is_active":    true,
+		"place_number": move.FromPlace,
+	}
+	// Moves only go to places within the capacity, so a session moved off a
+	// place beyond it is no longer out of range.
+	update := bson.M{
+		"$set":   bson.M{"place_number": move.ToPlace, "version": log.Version + 1},
+		"$unset": bson.M{"out_of_range_at": "", "overstayed_at": ""},
+		"$push":  bson.M{"moves": move},
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		r.logger.DebugContext(ctx, "Parking space log version conflict", "log_id", log.LogID, "version", log.Version)
+		return ErrVersionConflict
+	}
+
+	log.PlaceNumber = move.ToPlace
+	log.OutOfRangeAt = nil
+	log.OverstayedAt = nil
+	log.Moves = append(log.Moves, move)
+	log.Version++
+	return nil
+}
+
+// versionFilter matches documents written before versioning was introduced
+// as version 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// MarkParkingSpaceLogOverstayed records that an active session on placeNumber
+// overstayed at now, without changing its version. It reports false if the
+// session has ended, moved or been marked meanwhile.
+func (r *Repository) MarkParkingSpaceLogOverstayed(ctx context.Context, id primitive.ObjectID, placeNumber int, now time.Time) (bool, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{
+		"_id":           id,
+		"is_active":     true,
+		"place_number":  placeNumber,
+		"overstayed_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"overstayed_at": now}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// GetActiveParkingSpaceLogsStartedBefore returns active sessions that started
+// before the given time and have not been flagged as overstayed yet.
+func (r *Repository) GetActiveParkingSpaceLogsStartedBefore(ctx context.Context, before time.Time) ([]models.ParkingSpaceLog, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{
+		"is_active":     true,
+		"created_at":    bson.M{"$lte": before},
+		"overstayed_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.ParkingSpaceLog
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (r *Repository) GetOverstayedParkingSpaceLogs(ctx context.Context) ([]models.ParkingSpaceLog, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{"is_active": true, "overstayed_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.ParkingSpaceLog
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// FlagOutOfRangeParkingSpaceLogs marks active sessions on places beyond
+// slotsCount as out of range and clears the mark from sessions within it.
+// Only the mark is written and the version is left alone, so that clients
+// holding the session are not made to re-read it for a change they did not
+// make.
+func (r *Repository) FlagOutOfRangeParkingSpaceLogs(ctx context.Context, slotsCount int, now time.Time) (flagged, cleared int64, err error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+
+	result, err := collection.UpdateMany(ctx,
+		bson.M{
+			"is_active":       true,
+			"place_number":    bson.M{"$gt": slotsCount},
+			"out_of_range_at": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{"out_of_range_at": now},
+		},
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	flagged = result.ModifiedCount
+
+	result, err = collection.UpdateMany(ctx,
+		bson.M{
+			"is_active":       true,
+			"place_number":    bson.M{"$lte": slotsCount},
+			"out_of_range_at": bson.M{"$exists": true},
+		},
+		bson.M{
+			"$unset": bson.M{"out_of_range_at": ""},
+		},
+	)
+	if err != nil {
+		return flagged, 0, err
+	}
+	return flagged, result.ModifiedCount, nil
+}
+
+func (r *Repository) GetOutOfRangeParkingSpaceLogs(ctx context.Context) ([]models.ParkingSpaceLog, error) {
+	collection := r.db.Collection(models.ParkingSpaceLog{}.CollectionName())
+	filter := bson.M{"is_active": true, "out_of_range_at": bson.M{"$exists": true}}
+	opts := options.Find().SetSort(bson.D{{Key: "place_number", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.ParkingSpaceLog
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
 }
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amend-parking-backend/internal/metrics"
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/amend-parking-backend/internal/repository"
+)
+
+var (
+	ErrPlateDenied        = errors.New("license plate is denied access to this parking lot")
+	ErrPlateNotAllowed    = errors.New("only registered or allow-listed vehicles may park on this parking lot")
+	ErrUnknownPlateList   = errors.New("plate list not found, expected deny or allow")
+	ErrInvalidAccessMode  = errors.New("access mode must be open or allow_list")
+	ErrPlateAlreadyListed = errors.New("license plate is already on this list")
+	ErrPlateNotListed     = errors.New("license plate is not on this list")
+)
+
+// DefaultAuditLimit is the number of audit entries listed by default.
+const DefaultAuditLimit = 100
+
+// denialCollapseWindow is how long repeated denials of a plate are counted
+// in the same audit entry.
+const denialCollapseWindow = time.Hour
+
+// GetAccessPolicy returns the access mode of the lot. A lot whose mode was
+// never set is open.
+func (s *Service) GetAccessPolicy(ctx context.Context) (*models.AccessPolicy, error) {
+	lotID := s.Config().ParkingLotID
+	policy, err := s.repo.GetAccessPolicy(ctx, lotID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &models.AccessPolicy{LotID: lotID, Mode: models.AccessOpen}, nil
+	}
+	return policy, nil
+}
+
+// SetAccessMode switches the lot between admitting every vehicle and only
+// registered and allow-listed ones.
+func (s *Service) SetAccessMode(ctx context.Context, clientID, mode string) (_ *models.AccessPolicy, err error) {
+	ctx, span := tracer.Start(ctx, "Service.SetAccessMode")
+	defer func() { endSpan(span, err) }()
+
+	if mode != models.AccessOpen && mode != models.AccessAllowList {
+		return nil, ErrInvalidAccessMode
+	}
+
+	policy := &models.AccessPolicy{
+		LotID:     s.Config().ParkingLotID,
+		Mode:      mode,
+		UpdatedBy: clientID,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.SetAccessPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Access mode changed", "mode", mode)
+	s.audit(ctx, &models.AuditEntry{Action: models.AuditAccessModeChanged, Actor: clientID, Mode: mode})
+	return policy, nil
+}
+
+// GetPlateList returns the deny or allow list of the lot ordered by plate.
+func (s *Service) GetPlateList(ctx context.Context, list string) ([]models.PlateListEntry, error) {
+	if !isPlateList(list) {
+		return nil, ErrUnknownPlateList
+	}
+	return s.repo.GetPlateListEntries(ctx, s.Config().ParkingLotID, list)
+}
+
+// AddPlateListEntry puts a plate on the deny or allow list of the lot. The
+// plate is normalised, so every spelling of it is matched.
+func (s *Service) AddPlateListEntry(ctx context.Context, clientID, list, licensePlate, reason string) (_ *models.PlateListEntry, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AddPlateListEntry")
+	defer func() { endSpan(span, err) }()
+
+	if !isPlateList(list) {
+		return nil, ErrUnknownPlateList
+	}
+
+	entry := &models.PlateListEntry{
+		EntryID:      uuid.New().String(),
+		LotID:        s.Config().ParkingLotID,
+		List:         list,
+		LicensePlate: models.NormalizeLicensePlate(licensePlate),
+		Reason:       reason,
+		CreatedBy:    clientID,
+		CreatedAt:    time.Now().UTC(),
+	}
+	err = s.repo.AddPlateListEntry(ctx, entry)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrPlateAlreadyListed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "License plate listed", "list", list, "license_plate", entry.LicensePlate)
+	s.audit(ctx, &models.AuditEntry{
+		Action:       models.AuditPlateListed,
+		Actor:        clientID,
+		List:         list,
+		LicensePlate: entry.LicensePlate,
+		Reason:       reason,
+	})
+	return entry, nil
+}
+
+// RemovePlateListEntry takes a plate, in any spelling, off the deny or allow
+// list of the lot.
+func (s *Service) RemovePlateListEntry(ctx context.Context, clientID, list, licensePlate string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.RemovePlateListEntry")
+	defer func() { endSpan(span, err) }()
+
+	if !isPlateList(list) {
+		return ErrUnknownPlateList
+	}
+
+	licensePlate = models.NormalizeLicensePlate(licensePlate)
+	deleted, err := s.repo.DeletePlateListEntry(ctx, s.Config().ParkingLotID, list, licensePlate)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPlateNotListed
+	}
+
+	s.logger.InfoContext(ctx, "License plate unlisted", "list", list, "license_plate", licensePlate)
+	s.audit(ctx, &models.AuditEntry{
+		Action:       models.AuditPlateUnlisted,
+		Actor:        clientID,
+		List:         list,
+		LicensePlate: licensePlate,
+	})
+	return nil
+}
+
+// GetAuditLog returns the latest limit entries of the audit trail of the
+// lot, or DefaultAuditLimit if limit is not positive.
+func (s *Service) GetAuditLog(ctx context.Context, limit int) ([]models.AuditEntry, error) {
+	if limit <= 0 {
+		limit = DefaultAuditLimit
+	}
+	return s.repo.GetAuditEntries(ctx, s.Config().ParkingLotID, int64(limit))
+}
+
+// checkPlateAccess turns away a car whose plate is on the deny list of the
+// lot, or, in allow-list mode, a car that is neither registered nor on the
+// allow list. Cars turned away are recorded in the audit trail. Only the
+// lists and policy the decision needs are read.
+func (s *Service) checkPlateAccess(ctx context.Context, clientID, licensePlate string, vehicle *models.Vehicle) error {
+	lotID := s.Config().ParkingLotID
+	licensePlate = models.NormalizeLicensePlate(licensePlate)
+
+	denied, err := s.repo.GetPlateListEntry(ctx, lotID, models.PlateListDeny, licensePlate)
+	if err != nil {
+		return err
+	}
+	mode, allowListed := models.AccessOpen, false
+	if denied == nil && vehicle == nil {
+		policy, err := s.GetAccessPolicy(ctx)
+		if err != nil {
+			return err
+		}
+		mode = policy.Mode
+		if mode == models.AccessAllowList {
+			allowed, err := s.repo.GetPlateListEntry(ctx, lotID, models.PlateListAllow, licensePlate)
+			if err != nil {
+				return err
+			}
+			allowListed = allowed != nil
+		}
+	}
+
+	if list, reason := plateAccess(denied != nil, vehicle != nil, mode, allowListed); reason != nil {
+		return s.denyPark(ctx, clientID, licensePlate, list, reason)
+	}
+	return nil
+}
+
+// plateAccess decides whether a car may park: a plate on the deny list is
+// turned away whatever else holds, and in allow-list mode so is a plate that
+// is neither registered nor allow-listed. It returns the list that turned the
+// car away, if any, and the reason.
+func plateAccess(denyListed, registered bool, mode string, allowListed bool) (string, error) {
+	switch {
+	case denyListed:
+		return models.PlateListDeny, ErrPlateDenied
+	case registered, mode != models.AccessAllowList, allowListed:
+		return "", nil
+	}
+	return "", ErrPlateNotAllowed
+}
+
+// denyPark records a car turned away. Denials of the same plate within
+// denialCollapseWindow share an audit entry, and denial entries are dropped
+// after AUDIT_DENIAL_RETENTION, so that retries cannot grow the audit trail
+// without bound.
+func (s *Service) denyPark(ctx context.Context, clientID, licensePlate, list string, reason error) error {
+	cfg := s.Config()
+	s.metrics.AllocationFailuresTotal.WithLabelValues(cfg.ParkingLotID, metrics.AllocationFailureAccessDenied).Inc()
+	s.logger.WarnContext(ctx, "Car turned away", "license_plate", licensePlate, "reason", reason.Error())
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(cfg.AuditDenialRetention)
+	entry := &models.AuditEntry{
+		LotID:        cfg.ParkingLotID,
+		Action:       models.AuditParkDenied,
+		Actor:        clientID,
+		List:         list,
+		LicensePlate: licensePlate,
+		Reason:       reason.Error(),
+		At:           now,
+		ExpiresAt:    &expiresAt,
+	}
+	collapsed, err := s.repo.CollapseAuditEntry(ctx, entry, now.Add(-denialCollapseWindow))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Error recording audit entry", "action", entry.Action, "error", err)
+		return reason
+	}
+	if !collapsed {
+		entry.Count = 1
+		s.audit(ctx, entry)
+	}
+	return reason
+}
+
+// audit appends entry to the audit trail of the lot. The audited change has
+// already been made, so a failure to record it is logged rather than
+// returned.
+func (s *Service) audit(ctx context.Context, entry *models.AuditEntry) {
+	entry.EntryID = uuid.New().String()
+	entry.LotID = s.Config().ParkingLotID
+	if entry.At.IsZero() {
+		entry.At = time.Now().UTC()
+	}
+	if err := s.repo.AddAuditEntry(ctx, entry); err != nil {
+		s.logger.ErrorContext(ctx, "Error recording audit entry", "action", entry.Action, "error", err)
+	}
+}
+
+func isPlateList(list string) bool {
+	return list == models.PlateListDeny || list == models.PlateListAllow
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func TestPlateAccess(t *testing.T) {
+	tests := []struct {
+		name        string
+		denyListed  bool
+		registered  bool
+		mode        string
+		allowListed bool
+		wantList    string
+		wantErr     error
+	}{
+		{name: "open lot", mode: models.AccessOpen},
+		{name: "open lot, deny-listed", denyListed: true, mode: models.AccessOpen, wantList: models.PlateListDeny, wantErr: ErrPlateDenied},
+		{name: "open lot, registered and deny-listed", denyListed: true, registered: true, mode: models.AccessOpen, wantList: models.PlateListDeny, wantErr: ErrPlateDenied},
+		{name: "allow list, unknown plate", mode: models.AccessAllowList, wantErr: ErrPlateNotAllowed},
+		{name: "allow list, registered", registered: true, mode: models.AccessAllowList},
+		{name: "allow list, allow-listed", mode: models.AccessAllowList, allowListed: true},
+		{name: "allow list, allow- and deny-listed", denyListed: true, mode: models.AccessAllowList, allowListed: true, wantList: models.PlateListDeny, wantErr: ErrPlateDenied},
+		{name: "allow list, registered and deny-listed", denyListed: true, registered: true, mode: models.AccessAllowList, wantList: models.PlateListDeny, wantErr: ErrPlateDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := plateAccess(tt.denyListed, tt.registered, tt.mode, tt.allowListed)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if list != tt.wantList {
+				t.Errorf("list = %q, want %q", list, tt.wantList)
+			}
+		})
+	}
+}
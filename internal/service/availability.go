@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+// Statuses of a parking place.
+const (
+	PlaceFree         = "free"
+	PlaceOccupied     = "occupied"
+	PlaceReserved     = "reserved"
+	PlacePermitOnly   = "permit_only"
+	PlaceOutOfService = "out_of_service"
+)
+
+// AvailabilityCounts counts the places of a lot, or a part of it, by status.
+// Free places in PERMIT_ZONES are counted as PermitOnly rather than Free, as
+// only permit holders can take them.
+type AvailabilityCounts struct {
+	Total        int `json:"total" example:"52"`
+	Occupied     int `json:"occupied" example:"12"`
+	Free         int `json:"free" example:"40"`
+	Reserved     int `json:"reserved" example:"0"`
+	PermitOnly   int `json:"permit_only" example:"0"`
+	OutOfService int `json:"out_of_service" example:"0"`
+}
+
+func (c *AvailabilityCounts) add(status string) {
+	c.Total++
+	switch status {
+	case PlaceOccupied:
+		c.Occupied++
+	case PlaceReserved:
+		c.Reserved++
+	case PlacePermitOnly:
+		c.PermitOnly++
+	case PlaceOutOfService:
+		c.OutOfService++
+	default:
+		c.Free++
+	}
+}
+
+// Availability is the state of the places 1..PARKING_SLOTS_COUNT, broken
+// down by the zones, levels and space types of the space catalogue. Places
+// without a zone or level are left out of that breakdown. OutOfRange counts
+// the cars left beyond a reduced capacity, which are not part of Total.
+type Availability struct {
+	AvailabilityCounts
+	OutOfRange int                           `json:"out_of_range" example:"0"`
+	FreePlaces []int                         `json:"free_places" example:"1,2,5"`
+	ByZone     map[string]AvailabilityCounts `json:"by_zone"`
+	ByLevel    map[string]AvailabilityCounts `json:"by_level"`
+	ByType     map[string]AvailabilityCounts `json:"by_type"`
+}
+
+// GetAvailability reports the availability of the lot from a single
+// aggregation over the active sessions.
+func (s *Service) GetAvailability(ctx context.Context) (*Availability, error) {
+	cfg := s.Config()
+
+	occupiedPlaces, err := s.repo.GetOccupiedPlaceNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.reservedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := placeStatuses(cfg, occupiedPlaces, blocked, reserved)
+	availability := &Availability{
+		FreePlaces: []int{},
+		ByZone:     map[string]AvailabilityCounts{},
+		ByLevel:    map[string]AvailabilityCounts{},
+		ByType:     map[string]AvailabilityCounts{},
+	}
+	for _, placeNumber := range occupiedPlaces {
+		if placeNumber > cfg.ParkingSlotsCount {
+			availability.OutOfRange++
+		}
+	}
+	for i, status := range statuses {
+		placeNumber := i + 1
+		availability.add(status)
+		if status == PlaceFree {
+			availability.FreePlaces = append(availability.FreePlaces, placeNumber)
+		}
+		addTo(availability.ByZone, cfg.Spaces.Zone(placeNumber), status)
+		addTo(availability.ByLevel, cfg.Spaces.Level(placeNumber), status)
+		addTo(availability.ByType, cfg.Spaces.Type(placeNumber), status)
+	}
+	return availability, nil
+}
+
+// GetLotAvailability is GetAvailability for the lot lotID.
+func (s *Service) GetLotAvailability(ctx context.Context, lotID string) (*Availability, error) {
+	if lotID != s.Config().ParkingLotID {
+		return nil, ErrLotNotFound
+	}
+	return s.GetAvailability(ctx)
+}
+
+// placeStatuses returns the status of each place 1..PARKING_SLOTS_COUNT,
+// indexed by place number minus one. A car on a place blocked for
+// maintenance or reserved by a permit still occupies it, and a blocked place
+// is out of service even if it is reserved. Other places in PERMIT_ZONES are
+// permit_only instead of free.
+func placeStatuses(cfg *config.Config, occupiedPlaces []int, blocked map[int]*models.MaintenanceBlock, reserved map[int]*models.Permit) []string {
+	statuses := make([]string, cfg.ParkingSlotsCount)
+	for i := range statuses {
+		statuses[i] = PlaceFree
+		if cfg.IsPermitZone(i + 1) {
+			statuses[i] = PlacePermitOnly
+		}
+		if reserved[i+1] != nil {
+			statuses[i] = PlaceReserved
+		}
+		if blocked[i+1] != nil {
+			statuses[i] = PlaceOutOfService
+		}
+	}
+	for _, placeNumber := range occupiedPlaces {
+		if placeNumber >= 1 && placeNumber <= len(statuses) {
+			statuses[placeNumber-1] = PlaceOccupied
+		}
+	}
+	return statuses
+}
+
+func addTo(breakdown map[string]AvailabilityCounts, label, status string) {
+	if label == "" {
+		return
+	}
+	counts := breakdown[label]
+	counts.add(status)
+	breakdown[label] = counts
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func TestPlaceStatuses(t *testing.T) {
+	cfg := &config.Config{
+		ParkingSlotsCount: 6,
+		Spaces: config.SpaceCatalogue{
+			Zones: []config.PlaceLabel{
+				{FromPlace: 1, ToPlace: 4, Label: "A"},
+				{FromPlace: 5, ToPlace: 6, Label: "B"},
+			},
+		},
+		PermitZones: []string{"B"},
+	}
+	reservedPlace := 2
+
+	tests := []struct {
+		name     string
+		occupied []int
+		blocked  map[int]*models.MaintenanceBlock
+		reserved map[int]*models.Permit
+		want     []string
+	}{
+		{
+			name: "empty lot",
+			want: []string{PlaceFree, PlaceFree, PlaceFree, PlaceFree, PlacePermitOnly, PlacePermitOnly},
+		},
+		{
+			name:     "occupied places",
+			occupied: []int{1, 5},
+			want:     []string{PlaceOccupied, PlaceFree, PlaceFree, PlaceFree, PlaceOccupied, PlacePermitOnly},
+		},
+		{
+			name:     "occupied places beyond the lot ignored",
+			occupied: []int{0, 7},
+			want:     []string{PlaceFree, PlaceFree, PlaceFree, PlaceFree, PlacePermitOnly, PlacePermitOnly},
+		},
+		{
+			name:     "reserved and blocked places",
+			blocked:  map[int]*models.MaintenanceBlock{3: {}, 6: {}},
+			reserved: map[int]*models.Permit{reservedPlace: {PlaceNumber: &reservedPlace}},
+			want:     []string{PlaceFree, PlaceReserved, PlaceOutOfService, PlaceFree, PlacePermitOnly, PlaceOutOfService},
+		},
+		{
+			name:     "blocked place that is also reserved",
+			blocked:  map[int]*models.MaintenanceBlock{reservedPlace: {}},
+			reserved: map[int]*models.Permit{reservedPlace: {PlaceNumber: &reservedPlace}},
+			want:     []string{PlaceFree, PlaceOutOfService, PlaceFree, PlaceFree, PlacePermitOnly, PlacePermitOnly},
+		},
+		{
+			name:     "car on a blocked or reserved place",
+			occupied: []int{2, 3},
+			blocked:  map[int]*models.MaintenanceBlock{3: {}},
+			reserved: map[int]*models.Permit{reservedPlace: {PlaceNumber: &reservedPlace}},
+			want:     []string{PlaceFree, PlaceOccupied, PlaceOccupied, PlaceFree, PlacePermitOnly, PlacePermitOnly},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := placeStatuses(cfg, tt.occupied, tt.blocked, tt.reserved)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("placeStatuses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
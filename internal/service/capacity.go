@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+var ErrInvalidCapacity = errors.New("slot count must be positive")
+
+// Capacity describes the slot count of the lot and the active sessions left
+// on places beyond it after it was reduced.
+type Capacity struct {
+	SlotsCount int                      `json:"slots_count" example:"40"`
+	Occupied   int                      `json:"occupied" example:"38"`
+	Free       int                      `json:"free" example:"3"`
+	OutOfRange []models.ParkingSpaceLog `json:"out_of_range"`
+}
+
+func (s *Service) GetCapacity(ctx context.Context) (*Capacity, error) {
+	cfg := s.Config()
+
+	occupied, err := s.repo.GetCountOfOccupiedSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	free, err := s.countFreeSpaces(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	outOfRange, err := s.repo.GetOutOfRangeParkingSpaceLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if outOfRange == nil {
+		outOfRange = []models.ParkingSpaceLog{}
+	}
+
+	return &Capacity{
+		SlotsCount: cfg.ParkingSlotsCount,
+		Occupied:   int(occupied),
+		Free:       free,
+		OutOfRange: outOfRange,
+	}, nil
+}
+
+// SetCapacity changes the slot count at runtime. Unlike a configuration
+// reload it accepts a reduction below occupied places: those sessions are
+// flagged as out of range and their places are not allocated again. The cars
+// can still be freed or moved into range, which clears the flag. The change
+// survives reloads until PARKING_SLOTS_COUNT itself is changed, and lasts
+// until a restart.
+func (s *Service) SetCapacity(ctx context.Context, slotsCount int) (*Capacity, error) {
+	if slotsCount <= 0 {
+		return nil, ErrInvalidCapacity
+	}
+
+	s.configMu.Lock()
+	previous := s.Config()
+	s.cfg.Store(previous.WithSlotsCount(slotsCount))
+	s.configMu.Unlock()
+
+	s.logger.InfoContext(ctx, "Parking capacity changed", "from", previous.ParkingSlotsCount, "to", slotsCount)
+	if err := s.ReconcileCapacity(ctx); err != nil {
+		return nil, err
+	}
+	return s.GetCapacity(ctx)
+}
+
+// ReconcileCapacity flags the active sessions on places beyond the current
+// slot count and clears the flag from those within it, e.g. after a restart
+// with a smaller PARKING_SLOTS_COUNT.
+func (s *Service) ReconcileCapacity(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.ReconcileCapacity")
+	defer func() { endSpan(span, err) }()
+
+	slotsCount := s.Config().ParkingSlotsCount
+	flagged, cleared, err := s.repo.FlagOutOfRangeParkingSpaceLogs(ctx, slotsCount, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if flagged > 0 {
+		s.logger.WarnContext(ctx, "Parking sessions beyond the slot count", "slots_count", slotsCount, "flagged", flagged)
+	}
+	if cleared > 0 {
+		s.logger.InfoContext(ctx, "Parking sessions back within the slot count", "slots_count", slotsCount, "cleared", cleared)
+	}
+	return nil
+}
+
+// countFreeSpaces counts the free places within the slot count. Cars left
+// beyond a reduced capacity do not take places from it, while places blocked
+// for maintenance, reserved by permits or in PERMIT_ZONES are not free.
+func (s *Service) countFreeSpaces(ctx context.Context, cfg *config.Config) (int, error) {
+	occupiedPlaces, err := s.repo.GetOccupiedPlaceNumbers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+	reserved, err := s.reservedPlaces(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	free := 0
+	for _, status := range placeStatuses(cfg, occupiedPlaces, blocked, reserved) {
+		if status == PlaceFree {
+			free++
+		}
+	}
+	return free, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+// BeginIdempotentRequest reserves key for the request identified by
+// requestHash. It returns the stored record when the request has already been
+// completed and its response should be replayed, or nil when the caller
+// should execute the request and then call CompleteIdempotentRequest or
+// AbortIdempotentRequest.
+func (s *Service) BeginIdempotentRequest(ctx context.Context, key, requestHash string) (*models.IdempotencyKey, error) {
+	now := time.Now().UTC()
+	created, err := s.repo.CreateIdempotencyKey(ctx, &models.IdempotencyKey{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.Config().IdempotencyKeyTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return nil, nil
+	}
+
+	stored, err := s.repo.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		// The record expired between the insert attempt and the lookup.
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if stored.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !stored.Completed {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return stored, nil
+}
+
+func (s *Service) CompleteIdempotentRequest(ctx context.Context, key string, statusCode int, contentType, location string, body []byte) error {
+	return s.repo.CompleteIdempotencyKey(ctx, &models.IdempotencyKey{
+		Key:          key,
+		StatusCode:   statusCode,
+		ContentType:  contentType,
+		Location:     location,
+		ResponseBody: body,
+	})
+}
+
+// AbortIdempotentRequest releases key so that a retry executes the request
+// again, e.g. after an internal error.
+func (s *Service) AbortIdempotentRequest(ctx context.Context, key string) error {
+	return s.repo.DeleteIdempotencyKey(ctx, key)
+}
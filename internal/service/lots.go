@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+var ErrLotNotFound = errors.New("parking lot not found")
+
+// Lot summarises the occupancy of the parking lot served by this instance.
+type Lot struct {
+	LotID      string `json:"lot_id" example:"main"`
+	Title      string `json:"title" example:"Parking Service"`
+	SlotsCount int    `json:"slots_count" example:"52"`
+	Occupied   int    `json:"occupied" example:"12"`
+	Free       int    `json:"free" example:"40"`
+}
+
+// Space is a parking place together with the session occupying it and the
+// maintenance block in effect on it, if any.
+type Space struct {
+	PlaceNumber int                      `json:"place_number" example:"7"`
+	Occupied    bool                     `json:"occupied" example:"true"`
+	Session     *models.ParkingSpaceLog  `json:"session,omitempty"`
+	Block       *models.MaintenanceBlock `json:"block,omitempty"`
+}
+
+// GetLots lists the parking lots. An instance serves a single lot.
+func (s *Service) GetLots(ctx context.Context) ([]Lot, error) {
+	lot, err := s.GetLot(ctx, s.Config().ParkingLotID)
+	if err != nil {
+		return nil, err
+	}
+	return []Lot{*lot}, nil
+}
+
+func (s *Service) GetLot(ctx context.Context, lotID string) (*Lot, error) {
+	cfg := s.Config()
+	if lotID != cfg.ParkingLotID {
+		return nil, ErrLotNotFound
+	}
+
+	occupied, free, err := s.GetOccupancy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Lot{
+		LotID:      cfg.ParkingLotID,
+		Title:      cfg.AppTitle,
+		SlotsCount: cfg.ParkingSlotsCount,
+		Occupied:   occupied,
+		Free:       free,
+	}, nil
+}
+
+// GetSpaces lists the places of the lot in order, with their sessions.
+func (s *Service) GetSpaces(ctx context.Context) ([]Space, error) {
+	slotsCount := s.Config().ParkingSlotsCount
+
+	occupiedSpaces, err := s.repo.GetOccupiedSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	spaces := make([]Space, slotsCount)
+	for i := range spaces {
+		spaces[i].PlaceNumber = i + 1
+		spaces[i].Block = blocked[i+1]
+	}
+	for i := range occupiedSpaces {
+		session := &occupiedSpaces[i]
+		if session.PlaceNumber >= 1 && session.PlaceNumber <= slotsCount {
+			spaces[session.PlaceNumber-1].Occupied = true
+			spaces[session.PlaceNumber-1].Session = session
+		}
+	}
+	return spaces, nil
+}
+
+func (s *Service) GetSpace(ctx context.Context, placeNumber int) (*Space, error) {
+	if placeNumber < 1 || placeNumber > s.Config().ParkingSlotsCount {
+		return nil, ErrPlaceOutOfRange
+	}
+
+	session, err := s.repo.GetParkingSpaceLogByPlaceNumber(ctx, placeNumber)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Space{
+		PlaceNumber: placeNumber,
+		Occupied:    session != nil,
+		Session:     session,
+		Block:       blocked[placeNumber],
+	}, nil
+}
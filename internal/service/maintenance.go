@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amend-parking-backend/internal/models"
+)
+
+var (
+	ErrBlockNotFound      = errors.New("maintenance block not found")
+	ErrBlockFinished      = errors.New("maintenance block has already ended or expired")
+	ErrInvalidBlockPeriod = errors.New("maintenance block must end in the future and after it starts")
+	ErrPlaceOutOfService  = errors.New("parking space is out of service")
+)
+
+// CreateMaintenanceBlock takes placeNumber out of service from from, or now
+// if it is nil, until until, or until the block is ended if it is nil. A car
+// already on the place is not affected, but the place is not allocated again
+// while the block is in effect.
+func (s *Service) CreateMaintenanceBlock(ctx context.Context, clientID string, placeNumber int, reason string, from, until *time.Time) (_ *models.MaintenanceBlock, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreateMaintenanceBlock")
+	defer func() { endSpan(span, err) }()
+
+	if placeNumber < 1 || placeNumber > s.Config().ParkingSlotsCount {
+		return nil, ErrPlaceOutOfRange
+	}
+
+	now := time.Now().UTC()
+	block := &models.MaintenanceBlock{
+		BlockID:     uuid.New().String(),
+		PlaceNumber: placeNumber,
+		Reason:      reason,
+		From:        now,
+		CreatedBy:   clientID,
+		CreatedAt:   now,
+	}
+	if from != nil {
+		block.From = from.UTC()
+	}
+	if until != nil {
+		untilUTC := until.UTC()
+		if !untilUTC.After(block.From) || !untilUTC.After(now) {
+			return nil, ErrInvalidBlockPeriod
+		}
+		block.Until = &untilUTC
+	}
+
+	if err := s.repo.AddMaintenanceBlock(ctx, block); err != nil {
+		return nil, err
+	}
+	block.Status = block.StatusAt(now)
+	s.logger.InfoContext(ctx, "Parking space blocked", "block_id", block.BlockID, "place_number", placeNumber, "reason", reason, "status", block.Status)
+	return block, nil
+}
+
+func (s *Service) GetMaintenanceBlock(ctx context.Context, blockID string) (*models.MaintenanceBlock, error) {
+	block, err := s.repo.GetMaintenanceBlock(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, ErrBlockNotFound
+	}
+	block.Status = block.StatusAt(time.Now().UTC())
+	return block, nil
+}
+
+// GetMaintenanceBlocks lists the maintenance blocks, latest first. With
+// unfinished set, only the scheduled and active ones are listed.
+func (s *Service) GetMaintenanceBlocks(ctx context.Context, unfinished bool) ([]models.MaintenanceBlock, error) {
+	now := time.Now().UTC()
+	blocks, err := s.repo.GetMaintenanceBlocks(ctx, unfinished, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range blocks {
+		blocks[i].Status = blocks[i].StatusAt(now)
+	}
+	return blocks, nil
+}
+
+// EndMaintenanceBlock puts the place of a scheduled or active block back into
+// service.
+func (s *Service) EndMaintenanceBlock(ctx context.Context, blockID, clientID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.EndMaintenanceBlock")
+	defer func() { endSpan(span, err) }()
+
+	ended, err := s.repo.EndMaintenanceBlock(ctx, blockID, clientID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ended {
+		block, err := s.repo.GetMaintenanceBlock(ctx, blockID)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			return ErrBlockNotFound
+		}
+		return ErrBlockFinished
+	}
+
+	s.logger.InfoContext(ctx, "Parking space block ended", "block_id", blockID)
+	return nil
+}
+
+// blockedPlaces returns the active maintenance blocks by place number.
+func (s *Service) blockedPlaces(ctx context.Context) (map[int]*models.MaintenanceBlock, error) {
+	blocks, err := s.repo.GetActiveMaintenanceBlocks(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	blocked := make(map[int]*models.MaintenanceBlock, len(blocks))
+	for i := range blocks {
+		blocks[i].Status = models.BlockActive
+		if _, ok := blocked[blocks[i].PlaceNumber]; !ok {
+			blocked[blocks[i].PlaceNumber] = &blocks[i]
+		}
+	}
+	return blocked, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/events"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+// OverstayEvent is the payload of the session.overstayed event.
+type OverstayEvent struct {
+	Session models.ParkingSpaceLog `json:"session"`
+	MaxStay string                 `json:"max_stay"`
+}
+
+// RunOverstayMonitor periodically flags sessions that exceed their maximum
+// stay until ctx is cancelled.
+func (s *Service) RunOverstayMonitor(ctx context.Context) {
+	ticker := time.NewTicker(s.Config().OverstayCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := s.DetectOverstays(ctx); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Error detecting overstays", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DetectOverstays flags every active session that has been parked longer than
+// the maximum stay of its place and publishes a session.overstayed event for
+// each of them.
+func (s *Service) DetectOverstays(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.DetectOverstays")
+	defer func() { endSpan(span, err) }()
+
+	cfg := s.Config()
+	shortestStay := shortestMaxStay(cfg)
+	if shortestStay <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	candidates, err := s.repo.GetActiveParkingSpaceLogsStartedBefore(ctx, now.Add(-shortestStay))
+	if err != nil {
+		return err
+	}
+
+	for i := range candidates {
+		parkingSpaceLog := &candidates[i]
+
+		maxStay := cfg.MaxStayFor(parkingSpaceLog.PlaceNumber)
+		if maxStay <= 0 || now.Sub(parkingSpaceLog.CreatedAt) < maxStay {
+			continue
+		}
+
+		marked, err := s.repo.MarkParkingSpaceLogOverstayed(ctx, parkingSpaceLog.ID, parkingSpaceLog.PlaceNumber, now)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			// The session was ended or moved meanwhile; the next run decides again.
+			continue
+		}
+		overstayedAt := now
+		parkingSpaceLog.OverstayedAt = &overstayedAt
+
+		s.logger.WarnContext(ctx, "Parking session overstayed", "session", parkingSpaceLog, "max_stay", maxStay.String())
+		s.events.Publish(ctx, events.SessionOverstayed, OverstayEvent{
+			Session: *parkingSpaceLog,
+			MaxStay: maxStay.String(),
+		})
+	}
+
+	return nil
+}
+
+func (s *Service) GetOverstayedSessions(ctx context.Context) ([]models.ParkingSpaceLog, error) {
+	return s.repo.GetOverstayedParkingSpaceLogs(ctx)
+}
+
+// shortestMaxStay returns the smallest positive maximum stay of all rules, so
+// that only sessions which can possibly have overstayed are loaded.
+func shortestMaxStay(cfg *config.Config) time.Duration {
+	shortest := cfg.OverstayMaxStay
+	for _, rule := range cfg.OverstayRules {
+		if rule.MaxStay > 0 && (shortest <= 0 || rule.MaxStay < shortest) {
+			shortest = rule.MaxStay
+		}
+	}
+	return shortest
+}
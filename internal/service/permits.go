@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/events"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+var (
+	ErrPermitNotFound      = errors.New("permit not found")
+	ErrPermitFinished      = errors.New("permit has already been revoked or expired")
+	ErrInvalidPermitPeriod = errors.New("permit must end in the future and after it starts")
+	ErrUnknownPermitZone   = errors.New("permit zones must be listed in PERMIT_ZONES")
+	ErrPlaceReserved       = errors.New("parking space is reserved for a permit holder")
+	ErrPermitRequired      = errors.New("parking space is in a zone for permit holders")
+)
+
+// placeLockLease is how long CreatePermit may hold the lock on a dedicated
+// place. It only needs to cover a query and an insert.
+const placeLockLease = 10 * time.Second
+
+// PermitDetails are the fields of a permit set by clients.
+type PermitDetails struct {
+	LotID       string
+	VehicleID   string
+	DriverID    string
+	ValidFrom   *time.Time
+	ValidUntil  time.Time
+	Zones       []string
+	PlaceNumber *int
+}
+
+// PermitExpiryEvent is the payload of the permit.expiring event.
+type PermitExpiryEvent struct {
+	Permit    models.Permit `json:"permit"`
+	ExpiresIn string        `json:"expires_in"`
+}
+
+// CreatePermit issues a permit to a registered vehicle or driver, valid from
+// ValidFrom, or now if it is nil, until ValidUntil. A dedicated place may be
+// reserved by a single permit at a time; permits for the same place are
+// checked and written under a lock on the place, and a request finding it
+// locked gets ErrPlaceReserved.
+func (s *Service) CreatePermit(ctx context.Context, clientID string, details PermitDetails) (_ *models.Permit, err error) {
+	ctx, span := tracer.Start(ctx, "Service.CreatePermit")
+	defer func() { endSpan(span, err) }()
+
+	cfg := s.Config()
+	if details.LotID != "" && details.LotID != cfg.ParkingLotID {
+		return nil, ErrLotNotFound
+	}
+	for _, zone := range details.Zones {
+		if !slices.Contains(cfg.PermitZones, zone) {
+			return nil, ErrUnknownPermitZone
+		}
+	}
+
+	now := time.Now().UTC()
+	permit := &models.Permit{
+		PermitID:   uuid.New().String(),
+		LotID:      cfg.ParkingLotID,
+		VehicleID:  details.VehicleID,
+		DriverID:   details.DriverID,
+		ValidFrom:  now,
+		ValidUntil: details.ValidUntil.UTC(),
+		Zones:      details.Zones,
+		CreatedBy:  clientID,
+		CreatedAt:  now,
+	}
+	if details.ValidFrom != nil {
+		permit.ValidFrom = details.ValidFrom.UTC()
+	}
+	if !permit.ValidUntil.After(permit.ValidFrom) || !permit.ValidUntil.After(now) {
+		return nil, ErrInvalidPermitPeriod
+	}
+
+	if permit.VehicleID != "" {
+		if _, err := s.GetVehicle(ctx, permit.VehicleID); err != nil {
+			return nil, err
+		}
+	}
+	if permit.DriverID != "" {
+		if _, err := s.GetDriver(ctx, permit.DriverID); err != nil {
+			return nil, err
+		}
+	}
+
+	if details.PlaceNumber != nil {
+		placeNumber := *details.PlaceNumber
+		if placeNumber < 1 || placeNumber > cfg.ParkingSlotsCount {
+			return nil, ErrPlaceOutOfRange
+		}
+		lockedAt := time.Now().UTC()
+		locked, err := s.repo.LockPlace(ctx, cfg.ParkingLotID, placeNumber, permit.PermitID, lockedAt, lockedAt.Add(placeLockLease))
+		if err != nil {
+			return nil, err
+		}
+		if !locked {
+			return nil, ErrPlaceReserved
+		}
+		defer func() {
+			if unlockErr := s.repo.UnlockPlace(context.WithoutCancel(ctx), cfg.ParkingLotID, placeNumber, permit.PermitID); unlockErr != nil {
+				s.logger.WarnContext(ctx, "Error unlocking place", "place_number", placeNumber, "error", unlockErr)
+			}
+		}()
+
+		overlapping, err := s.repo.GetPermitsForPlace(ctx, cfg.ParkingLotID, placeNumber, permit.ValidFrom, permit.ValidUntil)
+		if err != nil {
+			return nil, err
+		}
+		if len(overlapping) > 0 {
+			return nil, ErrPlaceReserved
+		}
+		permit.PlaceNumber = &placeNumber
+	}
+
+	if err := s.repo.AddPermit(ctx, permit); err != nil {
+		return nil, err
+	}
+	permit.Status = permit.StatusAt(now)
+	s.logger.InfoContext(ctx, "Permit issued", "permit_id", permit.PermitID, "vehicle_id", permit.VehicleID, "driver_id", permit.DriverID, "valid_until", permit.ValidUntil)
+	return permit, nil
+}
+
+func (s *Service) GetPermit(ctx context.Context, permitID string) (*models.Permit, error) {
+	permit, err := s.repo.GetPermit(ctx, permitID)
+	if err != nil {
+		return nil, err
+	}
+	if permit == nil {
+		return nil, ErrPermitNotFound
+	}
+	permit.Status = permit.StatusAt(time.Now().UTC())
+	return permit, nil
+}
+
+// GetPermits lists the permits of the lot, latest first. With unfinished
+// set, only the scheduled and active ones are listed.
+func (s *Service) GetPermits(ctx context.Context, unfinished bool) ([]models.Permit, error) {
+	now := time.Now().UTC()
+	permits, err := s.repo.GetPermits(ctx, s.Config().ParkingLotID, unfinished, now)
+	if err != nil {
+		return nil, err
+	}
+	for i := range permits {
+		permits[i].Status = permits[i].StatusAt(now)
+	}
+	return permits, nil
+}
+
+// GetExpiringPermits lists the permits that expire within the given time,
+// or within PERMIT_EXPIRY_NOTICE if it is zero, soonest first.
+func (s *Service) GetExpiringPermits(ctx context.Context, within time.Duration) ([]models.Permit, error) {
+	cfg := s.Config()
+	if within <= 0 {
+		within = cfg.PermitExpiryNotice
+	}
+
+	now := time.Now().UTC()
+	permits, err := s.repo.GetExpiringPermits(ctx, cfg.ParkingLotID, now, now.Add(within))
+	if err != nil {
+		return nil, err
+	}
+	for i := range permits {
+		permits[i].Status = permits[i].StatusAt(now)
+	}
+	return permits, nil
+}
+
+// RevokePermit withdraws a scheduled or active permit. A car parked under it
+// stays where it is and pays the tariff from the revocation on.
+func (s *Service) RevokePermit(ctx context.Context, permitID, clientID string) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.RevokePermit")
+	defer func() { endSpan(span, err) }()
+
+	revoked, err := s.repo.RevokePermit(ctx, permitID, clientID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		permit, err := s.repo.GetPermit(ctx, permitID)
+		if err != nil {
+			return err
+		}
+		if permit == nil {
+			return ErrPermitNotFound
+		}
+		return ErrPermitFinished
+	}
+
+	s.logger.InfoContext(ctx, "Permit revoked", "permit_id", permitID)
+	return nil
+}
+
+// RunPermitMonitor periodically reminds of expiring permits until ctx is
+// cancelled.
+func (s *Service) RunPermitMonitor(ctx context.Context) {
+	ticker := time.NewTicker(s.Config().PermitCheckPeriod)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RemindExpiringPermits(ctx); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "Error reminding of expiring permits", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RemindExpiringPermits publishes a permit.expiring event once for every
+// permit that expires within PERMIT_EXPIRY_NOTICE.
+func (s *Service) RemindExpiringPermits(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "Service.RemindExpiringPermits")
+	defer func() { endSpan(span, err) }()
+
+	cfg := s.Config()
+	now := time.Now().UTC()
+	permits, err := s.repo.GetExpiringPermits(ctx, cfg.ParkingLotID, now, now.Add(cfg.PermitExpiryNotice))
+	if err != nil {
+		return err
+	}
+
+	for i := range permits {
+		permit := &permits[i]
+		if permit.ReminderSentAt != nil {
+			continue
+		}
+
+		marked, err := s.repo.MarkPermitReminded(ctx, permit.PermitID, now)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			continue
+		}
+
+		permit.ReminderSentAt = &now
+		permit.Status = permit.StatusAt(now)
+		expiresIn := permit.ValidUntil.Sub(now).Round(time.Minute)
+		s.logger.InfoContext(ctx, "Permit expiring", "permit_id", permit.PermitID, "valid_until", permit.ValidUntil)
+		s.events.Publish(ctx, events.PermitExpiring, PermitExpiryEvent{
+			Permit:    *permit,
+			ExpiresIn: expiresIn.String(),
+		})
+	}
+	return nil
+}
+
+// permitFor returns the permit a registered vehicle or driver parks under,
+// or nil if there is none. A permit with a dedicated place is preferred.
+func (s *Service) permitFor(ctx context.Context, vehicle *models.Vehicle, driver *models.Driver) (*models.Permit, error) {
+	var vehicleID, driverID string
+	if vehicle != nil {
+		vehicleID = vehicle.VehicleID
+	}
+	if driver != nil {
+		driverID = driver.DriverID
+	}
+	if vehicleID == "" && driverID == "" {
+		return nil, nil
+	}
+
+	permits, err := s.repo.GetActivePermits(ctx, s.Config().ParkingLotID, vehicleID, driverID, false, time.Now().UTC())
+	if err != nil || len(permits) == 0 {
+		return nil, err
+	}
+	for i := range permits {
+		if permits[i].PlaceNumber != nil {
+			permits[i].Status = models.PermitActive
+			return &permits[i], nil
+		}
+	}
+	permits[0].Status = models.PermitActive
+	return &permits[0], nil
+}
+
+// sessionPermit returns the permit an active session was parked under while
+// it is still in effect.
+func (s *Service) sessionPermit(ctx context.Context, parkingSpaceLog *models.ParkingSpaceLog) (*models.Permit, error) {
+	if parkingSpaceLog.PermitID == "" {
+		return nil, nil
+	}
+	permit, err := s.repo.GetPermit(ctx, parkingSpaceLog.PermitID)
+	if err != nil || permit == nil {
+		return nil, err
+	}
+	permit.Status = permit.StatusAt(time.Now().UTC())
+	if permit.Status != models.PermitActive {
+		return nil, nil
+	}
+	return permit, nil
+}
+
+// reservedPlaces returns the active permits with a dedicated place by place
+// number.
+func (s *Service) reservedPlaces(ctx context.Context) (map[int]*models.Permit, error) {
+	permits, err := s.repo.GetActivePermits(ctx, s.Config().ParkingLotID, "", "", true, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	reserved := make(map[int]*models.Permit, len(permits))
+	for i := range permits {
+		permits[i].Status = models.PermitActive
+		if _, ok := reserved[*permits[i].PlaceNumber]; !ok {
+			reserved[*permits[i].PlaceNumber] = &permits[i]
+		}
+	}
+	return reserved, nil
+}
+
+// permitCoverage returns how much of the time from from to to permit covers:
+// the overlap with its validity, ending early if it was revoked. A missing
+// permit covers nothing.
+func permitCoverage(permit *models.Permit, from, to time.Time) time.Duration {
+	if permit == nil {
+		return 0
+	}
+	start, end := permit.ValidFrom, permit.ValidUntil
+	if permit.RevokedAt != nil && permit.RevokedAt.Before(end) {
+		end = *permit.RevokedAt
+	}
+	if from.After(start) {
+		start = from
+	}
+	if to.Before(end) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// checkPlaceAccess reports whether the holder of permit, or a car without a
+// permit if it is nil, may take placeNumber: places reserved by other permits
+// are off limits, and places in PERMIT_ZONES need a permit for their zone.
+func checkPlaceAccess(cfg *config.Config, placeNumber int, reserved map[int]*models.Permit, permit *models.Permit) error {
+	if holder := reserved[placeNumber]; holder != nil && (permit == nil || holder.PermitID != permit.PermitID) {
+		return ErrPlaceReserved
+	}
+	if cfg.IsPermitZone(placeNumber) && (permit == nil || !permit.AllowsZone(cfg.Spaces.Zone(placeNumber))) {
+		return ErrPermitRequired
+	}
+	return nil
+}
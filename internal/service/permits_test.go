@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func TestPermitCoverage(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+	revokedAt := at(12)
+
+	tests := []struct {
+		name     string
+		permit   *models.Permit
+		from, to time.Time
+		want     time.Duration
+	}{
+		{
+			name: "no permit",
+			from: at(9), to: at(11),
+			want: 0,
+		},
+		{
+			name:   "session within the permit",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18)},
+			from:   at(9), to: at(11),
+			want: 2 * time.Hour,
+		},
+		{
+			name:   "session starting before the permit",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18)},
+			from:   at(6), to: at(10),
+			want: 2 * time.Hour,
+		},
+		{
+			name:   "session ending after the permit",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18)},
+			from:   at(17), to: at(20),
+			want: time.Hour,
+		},
+		{
+			name:   "session after the permit",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18)},
+			from:   at(19), to: at(20),
+			want: 0,
+		},
+		{
+			name:   "permit revoked during the session",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18), RevokedAt: &revokedAt},
+			from:   at(10), to: at(15),
+			want: 2 * time.Hour,
+		},
+		{
+			name:   "permit revoked before the session",
+			permit: &models.Permit{ValidFrom: at(8), ValidUntil: at(18), RevokedAt: &revokedAt},
+			from:   at(13), to: at(15),
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := permitCoverage(tt.permit, tt.from, tt.to); got != tt.want {
+				t.Errorf("permitCoverage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPlaceAccess(t *testing.T) {
+	cfg := &config.Config{
+		ParkingSlotsCount: 10,
+		Spaces: config.SpaceCatalogue{
+			Zones: []config.PlaceLabel{
+				{FromPlace: 1, ToPlace: 5, Label: "A"},
+				{FromPlace: 6, ToPlace: 8, Label: "B"},
+				{FromPlace: 9, ToPlace: 10, Label: "C"},
+			},
+		},
+		PermitZones: []string{"B", "C"},
+	}
+	dedicated := 3
+	holder := &models.Permit{PermitID: "holder", PlaceNumber: &dedicated}
+	reserved := map[int]*models.Permit{dedicated: holder}
+
+	tests := []struct {
+		name        string
+		placeNumber int
+		permit      *models.Permit
+		wantErr     error
+	}{
+		{name: "open place without a permit", placeNumber: 1},
+		{name: "open place with a permit", placeNumber: 1, permit: &models.Permit{PermitID: "other"}},
+		{name: "dedicated place of another permit", placeNumber: dedicated, wantErr: ErrPlaceReserved},
+		{name: "dedicated place of another permit with a permit", placeNumber: dedicated, permit: &models.Permit{PermitID: "other"}, wantErr: ErrPlaceReserved},
+		{name: "own dedicated place", placeNumber: dedicated, permit: holder},
+		{name: "permit zone without a permit", placeNumber: 6, wantErr: ErrPermitRequired},
+		{name: "permit zone with a permit for all zones", placeNumber: 6, permit: &models.Permit{PermitID: "other"}},
+		{name: "permit zone with a permit for it", placeNumber: 9, permit: &models.Permit{PermitID: "other", Zones: []string{"C"}}},
+		{name: "permit zone with a permit for another zone", placeNumber: 9, permit: &models.Permit{PermitID: "other", Zones: []string{"B"}}, wantErr: ErrPermitRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPlaceAccess(cfg, tt.placeNumber, reserved, tt.permit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("checkPlaceAccess() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+// Place is the state of a parking place on the lot map.
+type Place struct {
+	PlaceNumber int    `json:"place_number" example:"7"`
+	Zone        string `json:"zone,omitempty" example:"A"`
+	Level       string `json:"level,omitempty" example:"1"`
+	Type        string `json:"type" example:"standard"`
+	Status      string `json:"status" example:"occupied" enums:"free,permit_only,occupied,reserved,out_of_service"`
+	// Since is when the place got its status. It is missing for places that
+	// have never been used.
+	Since   *time.Time               `json:"since,omitempty" example:"2024-01-01T12:00:00Z"`
+	Session *PlaceSession            `json:"session,omitempty"`
+	Block   *models.MaintenanceBlock `json:"block,omitempty"`
+	// PermitID is the permit that reserves the place, if any.
+	PermitID string `json:"permit_id,omitempty" example:"9a4e2c1f-6b8d-4f3a-a7e5-0c2d4b6f8a1e"`
+}
+
+// PlaceSession summarises the session occupying a place. The map is shown
+// to every client, so it carries neither the licence plate nor the name of
+// the driver.
+type PlaceSession struct {
+	LogID        string     `json:"log_id" example:"0b6b1b9e-2a55-4d8e-9c36-1f9a5f1f5d3e"`
+	CarMake      string     `json:"car_make" example:"Toyota"`
+	StartedAt    time.Time  `json:"started_at" example:"2024-01-01T12:00:00Z"`
+	OverstayedAt *time.Time `json:"overstayed_at,omitempty" example:"2024-01-04T12:00:00Z"`
+}
+
+// PlaceMap is the state the lot map is built from. Its ETag changes whenever
+// the map would, so that clients polling the map can be answered without
+// building it.
+type PlaceMap struct {
+	cfg      *config.Config
+	sessions []models.ParkingSpaceLog
+	blocked  map[int]*models.MaintenanceBlock
+	reserved map[int]*models.Permit
+}
+
+// GetPlaceMap reads the active sessions, maintenance blocks and permits with
+// dedicated places the lot map is built from.
+func (s *Service) GetPlaceMap(ctx context.Context) (*PlaceMap, error) {
+	sessions, err := s.repo.GetOccupiedSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.reservedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The ETag must not depend on the order the sessions are returned in.
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LogID < sessions[j].LogID })
+	return &PlaceMap{cfg: s.Config(), sessions: sessions, blocked: blocked, reserved: reserved}, nil
+}
+
+// GetLotPlaceMap is GetPlaceMap for the lot lotID.
+func (s *Service) GetLotPlaceMap(ctx context.Context, lotID string) (*PlaceMap, error) {
+	if lotID != s.Config().ParkingLotID {
+		return nil, ErrLotNotFound
+	}
+	return s.GetPlaceMap(ctx)
+}
+
+// ETag identifies the map by the slot count, the space catalogue and permit
+// zones, the versions and overstay marks of the active sessions, the
+// maintenance blocks in effect and the permits reserving places.
+// Sessions that end or move change the set of active session versions, so
+// they also account for the times places were vacated.
+func (m *PlaceMap) ETag() string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\n%v\n%v\n", m.cfg.ParkingSlotsCount, m.cfg.Spaces, m.cfg.PermitZones)
+	for _, session := range m.sessions {
+		fmt.Fprintf(hash, "%s.%d", session.LogID, session.Version)
+		if session.OverstayedAt != nil {
+			fmt.Fprintf(hash, ".%d", session.OverstayedAt.Unix())
+		}
+		fmt.Fprintln(hash)
+	}
+	for placeNumber := 1; placeNumber <= m.cfg.ParkingSlotsCount; placeNumber++ {
+		if block := m.blocked[placeNumber]; block != nil {
+			fmt.Fprintf(hash, "%d:%s\n", placeNumber, block.BlockID)
+		}
+		if permit := m.reserved[placeNumber]; permit != nil {
+			fmt.Fprintf(hash, "%d:%s\n", placeNumber, permit.PermitID)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// GetPlaces builds the lot map: every place 1..PARKING_SLOTS_COUNT with its
+// status, the session occupying it and since when.
+func (s *Service) GetPlaces(ctx context.Context, m *PlaceMap) ([]Place, error) {
+	vacated, err := s.repo.GetPlaceVacatedTimes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]Place, m.cfg.ParkingSlotsCount)
+	for i := range places {
+		placeNumber := i + 1
+		places[i] = Place{
+			PlaceNumber: placeNumber,
+			Zone:        m.cfg.Spaces.Zone(placeNumber),
+			Level:       m.cfg.Spaces.Level(placeNumber),
+			Type:        m.cfg.Spaces.Type(placeNumber),
+			Status:      PlaceFree,
+		}
+		if m.cfg.IsPermitZone(placeNumber) {
+			places[i].Status = PlacePermitOnly
+		}
+		if at, ok := vacated[placeNumber]; ok {
+			places[i].Since = &at
+		}
+		if permit := m.reserved[placeNumber]; permit != nil {
+			places[i].Status = PlaceReserved
+			places[i].PermitID = permit.PermitID
+			if places[i].Since == nil || permit.ValidFrom.After(*places[i].Since) {
+				places[i].Since = &permit.ValidFrom
+			}
+		}
+		if block := m.blocked[placeNumber]; block != nil {
+			places[i].Status = PlaceOutOfService
+			places[i].Since = &block.From
+			places[i].Block = block
+		}
+	}
+
+	for _, session := range m.sessions {
+		if session.PlaceNumber < 1 || session.PlaceNumber > len(places) {
+			continue
+		}
+		since := occupiedSince(session)
+		place := &places[session.PlaceNumber-1]
+		place.Status = PlaceOccupied
+		place.Since = &since
+		place.Session = &PlaceSession{
+			LogID:        session.LogID,
+			CarMake:      session.CarMake,
+			StartedAt:    session.CreatedAt,
+			OverstayedAt: session.OverstayedAt,
+		}
+	}
+	return places, nil
+}
+
+// occupiedSince returns when the car of an active session arrived at its
+// current place.
+func occupiedSince(session models.ParkingSpaceLog) time.Time {
+	for i := len(session.Moves) - 1; i >= 0; i-- {
+		if session.Moves[i].ToPlace == session.PlaceNumber {
+			return session.Moves[i].MovedAt
+		}
+	}
+	return session.CreatedAt
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amend-parking-backend/internal/models"
+	"github.com/amend-parking-backend/internal/repository"
+)
+
+var (
+	ErrDriverNotFound     = errors.New("driver not found")
+	ErrDriverHasVehicles  = errors.New("driver still has registered vehicles")
+	ErrVehicleNotFound    = errors.New("vehicle not found")
+	ErrPlateRegistered    = errors.New("a vehicle with this license plate is already registered")
+	ErrCarDetailsRequired = errors.New("first_name, last_name, car_make and license_plate are required unless vehicle_id or a registered license_plate is given")
+)
+
+// DriverDetails are the fields of a driver set by clients.
+type DriverDetails struct {
+	FirstName string
+	LastName  string
+	Phone     string
+}
+
+// VehicleDetails are the fields of a vehicle set by clients.
+type VehicleDetails struct {
+	DriverID     string
+	LicensePlate string
+	Make         string
+	Model        string
+	Colour       string
+	SizeClass    string
+}
+
+func (s *Service) CreateDriver(ctx context.Context, details DriverDetails) (*models.Driver, error) {
+	now := time.Now().UTC()
+	driver := &models.Driver{
+		DriverID:  uuid.New().String(),
+		FirstName: details.FirstName,
+		LastName:  details.LastName,
+		Phone:     details.Phone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.AddDriver(ctx, driver); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+func (s *Service) GetDriver(ctx context.Context, driverID string) (*models.Driver, error) {
+	driver, err := s.repo.GetDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver == nil {
+		return nil, ErrDriverNotFound
+	}
+	return driver, nil
+}
+
+func (s *Service) GetDrivers(ctx context.Context) ([]models.Driver, error) {
+	return s.repo.GetDrivers(ctx)
+}
+
+func (s *Service) UpdateDriver(ctx context.Context, driverID string, details DriverDetails) (*models.Driver, error) {
+	driver, err := s.GetDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	driver.FirstName = details.FirstName
+	driver.LastName = details.LastName
+	driver.Phone = details.Phone
+	driver.UpdatedAt = time.Now().UTC()
+
+	replaced, err := s.repo.ReplaceDriver(ctx, driver)
+	if err != nil {
+		return nil, err
+	}
+	if !replaced {
+		return nil, ErrDriverNotFound
+	}
+	return driver, nil
+}
+
+// DeleteDriver removes a driver without vehicles. Past sessions keep the
+// names they were started with.
+func (s *Service) DeleteDriver(ctx context.Context, driverID string) error {
+	vehicles, err := s.repo.CountVehiclesOfDriver(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if vehicles > 0 {
+		return ErrDriverHasVehicles
+	}
+
+	deleted, err := s.repo.DeleteDriver(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrDriverNotFound
+	}
+	return nil
+}
+
+func (s *Service) CreateVehicle(ctx context.Context, details VehicleDetails) (*models.Vehicle, error) {
+	if _, err := s.GetDriver(ctx, details.DriverID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	vehicle := &models.Vehicle{
+		VehicleID: uuid.New().String(),
+		CreatedAt: now,
+	}
+	setVehicleDetails(vehicle, details, now)
+
+	err := s.repo.AddVehicle(ctx, vehicle)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrPlateRegistered
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vehicle, nil
+}
+
+func (s *Service) GetVehicle(ctx context.Context, vehicleID string) (*models.Vehicle, error) {
+	vehicle, err := s.repo.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	return vehicle, nil
+}
+
+// GetVehicleByLicensePlate looks a vehicle up by its plate in any spelling
+// NormalizeLicensePlate accepts.
+func (s *Service) GetVehicleByLicensePlate(ctx context.Context, licensePlate string) (*models.Vehicle, error) {
+	vehicle, err := s.repo.GetVehicleByLicensePlate(ctx, models.NormalizeLicensePlate(licensePlate))
+	if err != nil {
+		return nil, err
+	}
+	if vehicle == nil {
+		return nil, ErrVehicleNotFound
+	}
+	return vehicle, nil
+}
+
+// GetVehicles lists the vehicles, only those of driverID if it is set.
+func (s *Service) GetVehicles(ctx context.Context, driverID string) ([]models.Vehicle, error) {
+	if driverID != "" {
+		if _, err := s.GetDriver(ctx, driverID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetVehicles(ctx, driverID)
+}
+
+func (s *Service) UpdateVehicle(ctx context.Context, vehicleID string, details VehicleDetails) (*models.Vehicle, error) {
+	vehicle, err := s.GetVehicle(ctx, vehicleID)
+	if err != nil {
+		return nil, err
+	}
+	if details.DriverID != vehicle.DriverID {
+		if _, err := s.GetDriver(ctx, details.DriverID); err != nil {
+			return nil, err
+		}
+	}
+	setVehicleDetails(vehicle, details, time.Now().UTC())
+
+	replaced, err := s.repo.ReplaceVehicle(ctx, vehicle)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrPlateRegistered
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !replaced {
+		return nil, ErrVehicleNotFound
+	}
+	return vehicle, nil
+}
+
+// DeleteVehicle removes a vehicle from the registry. Its sessions keep the
+// car details they were started with.
+func (s *Service) DeleteVehicle(ctx context.Context, vehicleID string) error {
+	deleted, err := s.repo.DeleteVehicle(ctx, vehicleID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrVehicleNotFound
+	}
+	return nil
+}
+
+func setVehicleDetails(vehicle *models.Vehicle, details VehicleDetails, now time.Time) {
+	vehicle.DriverID = details.DriverID
+	vehicle.LicensePlate = models.NormalizeLicensePlate(details.LicensePlate)
+	vehicle.Make = details.Make
+	vehicle.Model = details.Model
+	vehicle.Colour = details.Colour
+	vehicle.SizeClass = details.SizeClass
+	vehicle.UpdatedAt = now
+}
+
+// ParkRequest identifies the car to park: a registered vehicle by its ID or
+// plate, or a car given by all of its details. Names and make given along
+// with a registered vehicle take precedence over the registry, e.g. when
+// someone else drives the car.
+type ParkRequest struct {
+	VehicleID    string
+	FirstName    string
+	LastName     string
+	CarMake      string
+	LicensePlate string
+}
+
+// resolveCar completes req from the vehicle registry. It returns the
+// registered vehicle and its driver, or nil for an unregistered car.
+func (s *Service) resolveCar(ctx context.Context, req *ParkRequest) (*models.Vehicle, *models.Driver, error) {
+	var vehicle *models.Vehicle
+	var err error
+	switch {
+	case req.VehicleID != "":
+		vehicle, err = s.GetVehicle(ctx, req.VehicleID)
+		if err != nil {
+			return nil, nil, err
+		}
+	case req.LicensePlate != "":
+		vehicle, err = s.repo.GetVehicleByLicensePlate(ctx, models.NormalizeLicensePlate(req.LicensePlate))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var driver *models.Driver
+	if vehicle != nil {
+		driver, err = s.repo.GetDriver(ctx, vehicle.DriverID)
+		if err != nil {
+			return nil, nil, err
+		}
+		req.LicensePlate = vehicle.LicensePlate
+		req.CarMake = firstNonEmpty(req.CarMake, vehicle.Make)
+		if driver != nil {
+			req.FirstName = firstNonEmpty(req.FirstName, driver.FirstName)
+			req.LastName = firstNonEmpty(req.LastName, driver.LastName)
+		}
+	}
+
+	if req.FirstName == "" || req.LastName == "" || req.CarMake == "" || req.LicensePlate == "" {
+		return nil, nil, ErrCarDetailsRequired
+	}
+	return vehicle, driver, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
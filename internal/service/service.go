@@ -2,106 +2,511 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/events"
+	"github.com/amend-parking-backend/internal/metrics"
 	"github.com/amend-parking-backend/internal/models"
 	"github.com/amend-parking-backend/internal/repository"
 	"github.com/google/uuid"
 )
 
+var (
+	ErrNoFreeSpaces        = errors.New("no free parking spaces available")
+	ErrSpaceAlreadyFree    = errors.New("parking space is already free")
+	ErrSessionNotFound     = errors.New("parking session not found")
+	ErrSessionNotActive    = errors.New("parking session is not active")
+	ErrPlaceOccupied       = errors.New("parking space is occupied")
+	ErrPlaceOutOfRange     = errors.New("parking space does not exist")
+	ErrSessionAlreadyThere = errors.New("car is already parked at this place")
+	ErrSessionChanged      = errors.New("parking session was changed concurrently, retry the request")
+	ErrPreconditionFailed  = errors.New("parking session has been modified since it was read")
+	ErrPlacesStranded      = errors.New("occupied parking spaces would be beyond the slot count")
+	ErrSessionLimitReached = errors.New("too many active parking sessions for this client")
+)
+
 type Service struct {
-	repo *repository.Repository
+	cfg      atomic.Pointer[config.Config]
+	configMu sync.Mutex
+	repo     *repository.Repository
+	events   *events.Publisher
+	metrics  *metrics.Metrics
+	logger   *slog.Logger
+}
+
+func NewService(cfg *config.Config, repo *repository.Repository, publisher *events.Publisher, metrics *metrics.Metrics, logger *slog.Logger) *Service {
+	s := &Service{repo: repo, events: publisher, metrics: metrics, logger: logger}
+	s.cfg.Store(cfg)
+	return s
+}
+
+// Config returns the current configuration. Callers should read it once per
+// operation so that they see a consistent snapshot across a reload.
+func (s *Service) Config() *config.Config {
+	return s.cfg.Load()
 }
 
-func NewService(repo *repository.Repository) *Service {
-	return &Service{repo: repo}
+// ApplyReload merges the reloadable settings of a reloaded configuration
+// into the current one and swaps the result in. Reading, merging and swapping
+// happen under the same lock as SetCapacity, so a concurrent capacity change
+// is either kept or seen by the merge, never lost. It refuses to reduce the
+// slot count below an occupied place; such a reduction has to be made
+// deliberately with SetCapacity. Sessions already flagged as out of range
+// after such a reduction do not count.
+func (s *Service) ApplyReload(ctx context.Context, next *config.Config) (config.ReloadResult, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	previous := s.Config()
+	result := previous.ApplyReload(next)
+	if len(result.Changed) == 0 {
+		return result, nil
+	}
+
+	cfg := result.Config
+	if cfg.ParkingSlotsCount < previous.ParkingSlotsCount {
+		occupiedSpaces, err := s.repo.GetOccupiedSpaces(ctx)
+		if err != nil {
+			return config.ReloadResult{}, err
+		}
+
+		var stranded []int
+		for _, space := range occupiedSpaces {
+			if space.PlaceNumber > cfg.ParkingSlotsCount && space.OutOfRangeAt == nil {
+				stranded = append(stranded, space.PlaceNumber)
+			}
+		}
+		if len(stranded) > 0 {
+			sort.Ints(stranded)
+			return config.ReloadResult{}, fmt.Errorf("%w: places %v are occupied, use the capacity endpoint to reduce it anyway", ErrPlacesStranded, stranded)
+		}
+	}
+
+	s.cfg.Store(cfg)
+	if previous.ParkingSlotsCount != cfg.ParkingSlotsCount {
+		return result, s.ReconcileCapacity(ctx)
+	}
+	return result, nil
 }
 
 func (s *Service) GetCountOfFreeSpaces(ctx context.Context) (int, error) {
+	return s.countFreeSpaces(ctx, s.Config())
+}
+
+// GetOccupancy reports the number of occupied and free spaces of the lot.
+func (s *Service) GetOccupancy(ctx context.Context) (int, int, error) {
 	occupiedCount, err := s.repo.GetCountOfOccupiedSpaces(ctx)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
+	}
+	freeCount, err := s.countFreeSpaces(ctx, s.Config())
+	if err != nil {
+		return 0, 0, err
 	}
-	return config.Settings.ParkingSlotsCount - int(occupiedCount), nil
+	return int(occupiedCount), freeCount, nil
 }
 
 func (s *Service) GetOccupiedSpaces(ctx context.Context) ([]models.ParkingSpaceLog, error) {
 	return s.repo.GetOccupiedSpaces(ctx)
 }
 
-func (s *Service) AddParkingSpaceLog(ctx context.Context, firstName, lastName, carMake, licensePlate string) (*models.ParkingSpaceLog, error) {
-	occupiedSpaces, err := s.repo.GetOccupiedSpaces(ctx)
+// AddParkingSpaceLog parks a car on a free place on behalf of clientID. The
+// car details are completed from the vehicle registry, see ParkRequest. A car
+// turned away by the plate lists of the lot gets ErrPlateDenied or
+// ErrPlateNotAllowed. A registered car with an active permit parks on its
+// dedicated place while that is free, may use the permit zones of the permit
+// and pays no tariff. A client may hold at most
+// MAX_ACTIVE_SESSIONS_PER_CLIENT active sessions, so that a leaked key
+// cannot fill the lot. The limit is checked before and again after the
+// session is added: of the sessions that concurrent requests added, the
+// oldest ones within the limit are kept and the others removed again.
+func (s *Service) AddParkingSpaceLog(ctx context.Context, clientID string, req ParkRequest) (_ *models.ParkingSpaceLog, err error) {
+	ctx, span := tracer.Start(ctx, "Service.AddParkingSpaceLog")
+	defer func() { endSpan(span, err) }()
+
+	vehicle, driver, err := s.resolveCar(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
-
-	if len(occupiedSpaces) >= config.Settings.ParkingSlotsCount {
-		return nil, fmt.Errorf("no free parking spaces available")
+	if err := s.checkPlateAccess(ctx, clientID, req.LicensePlate, vehicle); err != nil {
+		return nil, err
 	}
-
-	occupiedPlaceNumbers := make(map[int]bool)
-	for _, space := range occupiedSpaces {
-		occupiedPlaceNumbers[space.PlaceNumber] = true
+	permit, err := s.permitFor(ctx, vehicle, driver)
+	if err != nil {
+		return nil, err
 	}
 
-	var availablePlaces []int
-	for i := 1; i <= config.Settings.ParkingSlotsCount; i++ {
-		if !occupiedPlaceNumbers[i] {
-			availablePlaces = append(availablePlaces, i)
+	cfg := s.Config()
+	limited := cfg.MaxActiveSessions > 0 && clientID != ""
+	if limited {
+		active, err := s.repo.GetCountOfActiveParkingSpaceLogsCreatedBy(ctx, clientID)
+		if err != nil {
+			return nil, err
+		}
+		if active >= int64(cfg.MaxActiveSessions) {
+			return nil, s.sessionLimitReached(ctx, cfg, clientID, active)
 		}
 	}
 
-	if len(availablePlaces) == 0 {
-		return nil, fmt.Errorf("no free parking spaces available")
+	occupiedSpaces, err := s.repo.GetOccupiedSpaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := s.blockedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.reservedPlaces(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	selectedPlace := availablePlaces[rand.Intn(len(availablePlaces))]
+	selectedPlace, ok := pickFreePlace(cfg, occupiedSpaces, blocked, reserved, permit)
+	if !ok {
+		s.metrics.AllocationFailuresTotal.WithLabelValues(cfg.ParkingLotID, metrics.AllocationFailureLotFull).Inc()
+		s.logger.WarnContext(ctx, "No free parking spaces available", "occupied", len(occupiedSpaces))
+		return nil, ErrNoFreeSpaces
+	}
 
 	parkingSpaceLog := &models.ParkingSpaceLog{
 		LogID:        uuid.New().String(),
 		PlaceNumber:  selectedPlace,
-		FirstName:    firstName,
-		LastName:     lastName,
-		CarMake:      carMake,
-		LicensePlate: licensePlate,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		CarMake:      req.CarMake,
+		LicensePlate: req.LicensePlate,
 		CreatedAt:    time.Now().UTC(),
 		IsActive:     true,
+		CreatedBy:    clientID,
+	}
+	if vehicle != nil {
+		parkingSpaceLog.VehicleID = vehicle.VehicleID
+	}
+	if driver != nil {
+		parkingSpaceLog.DriverID = driver.DriverID
+	}
+	if permit != nil {
+		parkingSpaceLog.PermitID = permit.PermitID
 	}
 
 	err = s.repo.AddParkingSpaceLog(ctx, parkingSpaceLog)
+	if errors.Is(err, repository.ErrDuplicate) {
+		// Another request took the place between reading the free places
+		// and the insert.
+		return nil, ErrPlaceOccupied
+	}
 	if err != nil {
 		return nil, err
 	}
+	if limited {
+		// Requests of the same client that passed the check at the same time
+		// may have added sessions meanwhile. All of them agree to keep the
+		// sessions with the lowest IDs, so the one that backs out is the one
+		// outside the limit and not every one that sees it exceeded.
+		kept, err := s.repo.GetOldestActiveParkingSpaceLogIDsCreatedBy(ctx, clientID, int64(cfg.MaxActiveSessions))
+		if err != nil {
+			s.logger.WarnContext(ctx, "Error rechecking the active session limit", "client_id", clientID, "error", err)
+		} else if !slices.Contains(kept, parkingSpaceLog.ID) {
+			if err := s.repo.DeleteParkingSpaceLog(ctx, parkingSpaceLog.ID); err != nil {
+				return nil, err
+			}
+			return nil, s.sessionLimitReached(ctx, cfg, clientID, int64(len(kept)))
+		}
+	}
+	s.metrics.CarsParkedTotal.WithLabelValues(cfg.ParkingLotID).Inc()
+	s.logger.InfoContext(ctx, "Car parked", "session", parkingSpaceLog)
 
 	return parkingSpaceLog, nil
 }
 
-func (s *Service) FreeUpParkingSpace(ctx context.Context, placeNumber int) (*models.ParkingSpaceLog, error) {
+func (s *Service) sessionLimitReached(ctx context.Context, cfg *config.Config, clientID string, active int64) error {
+	s.metrics.AllocationFailuresTotal.WithLabelValues(cfg.ParkingLotID, metrics.AllocationFailureClientLimit).Inc()
+	s.logger.WarnContext(ctx, "Active session limit reached", "client_id", clientID, "active", active)
+	return ErrSessionLimitReached
+}
+
+func (s *Service) FreeUpParkingSpace(ctx context.Context, placeNumber int) (_ *models.ParkingSpaceLog, err error) {
+	ctx, span := tracer.Start(ctx, "Service.FreeUpParkingSpace")
+	defer func() { endSpan(span, err) }()
+
 	parkingSpaceLog, err := s.repo.GetParkingSpaceLogByPlaceNumber(ctx, placeNumber)
 	if err != nil {
 		return nil, err
 	}
 
 	if parkingSpaceLog == nil {
-		return nil, fmt.Errorf("parking space is already free")
+		return nil, ErrSpaceAlreadyFree
+	}
+
+	parkingSpaceLog, err = s.endParkingSession(ctx, parkingSpaceLog)
+	if err != nil {
+		return nil, versionConflictError(err, nil)
+	}
+	return parkingSpaceLog, nil
+}
+
+func (s *Service) GetParkingSession(ctx context.Context, logID string) (*models.ParkingSpaceLog, error) {
+	parkingSpaceLog, err := s.repo.GetParkingSpaceLogByLogID(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if parkingSpaceLog == nil {
+		return nil, ErrSessionNotFound
 	}
+	return parkingSpaceLog, nil
+}
 
+// EndParkingSession ends the session identified by logID. If expectedVersion
+// is set, the session is only ended while it still has that version.
+func (s *Service) EndParkingSession(ctx context.Context, logID string, expectedVersion *int64) (_ *models.ParkingSpaceLog, err error) {
+	ctx, span := tracer.Start(ctx, "Service.EndParkingSession")
+	defer func() { endSpan(span, err) }()
+
+	parkingSpaceLog, err := s.GetParkingSession(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && *expectedVersion != parkingSpaceLog.Version {
+		return nil, ErrPreconditionFailed
+	}
+	if !parkingSpaceLog.IsActive {
+		return nil, ErrSessionNotActive
+	}
+
+	parkingSpaceLog, err = s.endParkingSession(ctx, parkingSpaceLog)
+	if err != nil {
+		return nil, versionConflictError(err, expectedVersion)
+	}
+	return parkingSpaceLog, nil
+}
+
+// GetParkingReceipt bills every started hour of the session at the configured
+// hourly rate. For a session parked under a permit only the time outside the
+// validity of the permit, or after it was revoked, is billed. For a session
+// that is still active the receipt is preliminary and calculated up to the
+// current moment.
+func (s *Service) GetParkingReceipt(ctx context.Context, logID string) (*models.ParkingReceipt, error) {
+	parkingSpaceLog, err := s.GetParkingSession(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	endedAt := time.Now().UTC()
+	if parkingSpaceLog.FreeUpTime != nil {
+		endedAt = *parkingSpaceLog.FreeUpTime
+	}
+
+	duration := endedAt.Sub(parkingSpaceLog.CreatedAt)
+	if duration < 0 {
+		duration = 0
+	}
+	var covered time.Duration
+	if parkingSpaceLog.PermitID != "" {
+		permit, err := s.repo.GetPermit(ctx, parkingSpaceLog.PermitID)
+		if err != nil {
+			return nil, err
+		}
+		covered = permitCoverage(permit, parkingSpaceLog.CreatedAt, endedAt)
+	}
+	billedHours := int((duration - covered + time.Hour - 1) / time.Hour)
+	hourlyRate := s.Config().ParkingHourlyRate
+
+	return &models.ParkingReceipt{
+		LogID:           parkingSpaceLog.LogID,
+		PlaceNumber:     parkingSpaceLog.PlaceNumber,
+		FirstName:       parkingSpaceLog.FirstName,
+		LastName:        parkingSpaceLog.LastName,
+		CarMake:         parkingSpaceLog.CarMake,
+		LicensePlate:    parkingSpaceLog.LicensePlate,
+		StartedAt:       parkingSpaceLog.CreatedAt,
+		EndedAt:         endedAt,
+		IsFinal:         !parkingSpaceLog.IsActive,
+		DurationMinutes: int(duration / time.Minute),
+		BilledHours:     billedHours,
+		HourlyRate:      hourlyRate,
+		Amount:          billedHours * hourlyRate,
+		PermitID:        parkingSpaceLog.PermitID,
+		PermitMinutes:   int(covered / time.Minute),
+		Moves:           parkingSpaceLog.Moves,
+	}, nil
+}
+
+func (s *Service) endParkingSession(ctx context.Context, parkingSpaceLog *models.ParkingSpaceLog) (*models.ParkingSpaceLog, error) {
 	now := time.Now().UTC()
 	parkingSpaceLog.IsActive = false
 	parkingSpaceLog.FreeUpTime = &now
 
-	err = s.repo.UpdateParkingSpaceLog(ctx, parkingSpaceLog)
+	err := s.repo.UpdateParkingSpaceLog(ctx, parkingSpaceLog)
 	if err != nil {
 		return nil, err
 	}
 
+	s.recordPlaceVacated(ctx, parkingSpaceLog.PlaceNumber, now)
+	lot := s.Config().ParkingLotID
+	s.metrics.SpacesFreedTotal.WithLabelValues(lot).Inc()
+	s.metrics.SessionDuration.WithLabelValues(lot).Observe(now.Sub(parkingSpaceLog.CreatedAt).Seconds())
+	s.logger.InfoContext(ctx, "Parking session ended", "session", parkingSpaceLog)
+
 	return parkingSpaceLog, nil
 }
 
 func (s *Service) GetParkingSpaceLogsByFirstNameAndLastName(ctx context.Context, firstName, lastName string) ([]models.ParkingSpaceLog, error) {
 	return s.repo.GetParkingSpaceLogsByFirstNameAndLastName(ctx, firstName, lastName)
 }
+
+// MoveParkingSpaceLog relocates the car of an active session to another place
+// while keeping the session itself, so billing continues uninterrupted. If
+// placeNumber is nil a random free place is chosen. If expectedVersion is set,
+// the session is only moved while it still has that version.
+func (s *Service) MoveParkingSpaceLog(ctx context.Context, logID string, placeNumber *int, expectedVersion *int64) (_ *models.ParkingSpaceLog, err error) {
+	ctx, span := tracer.Start(ctx, "Service.MoveParkingSpaceLog")
+	defer func() { endSpan(span, err) }()
+
+	cfg := s.Config()
+	parkingSpaceLog, err := s.GetParkingSession(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if expectedVersion != nil && *expectedVersion != parkingSpaceLog.Version {
+		return nil, ErrPreconditionFailed
+	}
+	if !parkingSpaceLog.IsActive {
+		return nil, ErrSessionNotActive
+	}
+	permit, err := s.sessionPermit(ctx, parkingSpaceLog)
+	if err != nil {
+		return nil, err
+	}
+	reserved, err := s.reservedPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetPlace int
+	if placeNumber != nil {
+		targetPlace = *placeNumber
+		if targetPlace < 1 || targetPlace > cfg.ParkingSlotsCount {
+			return nil, ErrPlaceOutOfRange
+		}
+		if targetPlace == parkingSpaceLog.PlaceNumber {
+			return nil, ErrSessionAlreadyThere
+		}
+
+		blocked, err := s.blockedPlaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if blocked[targetPlace] != nil {
+			return nil, ErrPlaceOutOfService
+		}
+		if err := checkPlaceAccess(cfg, targetPlace, reserved, permit); err != nil {
+			return nil, err
+		}
+
+		occupant, err := s.repo.GetParkingSpaceLogByPlaceNumber(ctx, targetPlace)
+		if err != nil {
+			return nil, err
+		}
+		if occupant != nil {
+			return nil, ErrPlaceOccupied
+		}
+	} else {
+		occupiedSpaces, err := s.repo.GetOccupiedSpaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blocked, err := s.blockedPlaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		targetPlace, ok = pickFreePlace(cfg, occupiedSpaces, blocked, reserved, permit)
+		if !ok {
+			return nil, ErrNoFreeSpaces
+		}
+	}
+
+	move := models.PlaceMove{
+		FromPlace: parkingSpaceLog.PlaceNumber,
+		ToPlace:   targetPlace,
+		MovedAt:   time.Now().UTC(),
+	}
+
+	err = s.repo.MoveParkingSpaceLog(ctx, parkingSpaceLog, move)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, ErrPlaceOccupied
+	}
+	if err != nil {
+		return nil, versionConflictError(err, expectedVersion)
+	}
+	s.recordPlaceVacated(ctx, move.FromPlace, move.MovedAt)
+	s.logger.InfoContext(ctx, "Car moved", "session", parkingSpaceLog, "from_place", move.FromPlace)
+
+	return parkingSpaceLog, nil
+}
+
+// recordPlaceVacated keeps the time shown on the lot map for a place that was
+// just vacated. The session has already been updated, so a failure only
+// leaves the map with an older time and is logged rather than returned.
+func (s *Service) recordPlaceVacated(ctx context.Context, placeNumber int, at time.Time) {
+	if err := s.repo.RecordPlaceVacated(ctx, placeNumber, at); err != nil {
+		s.logger.ErrorContext(ctx, "Error recording vacated place", "place_number", placeNumber, "error", err)
+	}
+}
+
+// versionConflictError translates a lost optimistic update into the error the
+// caller can act on: a failed precondition if it asked for a specific
+// version, a concurrent change otherwise.
+func versionConflictError(err error, expectedVersion *int64) error {
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+	if expectedVersion != nil {
+		return ErrPreconditionFailed
+	}
+	return ErrSessionChanged
+}
+
+// pickFreePlace chooses a free place that is not blocked for maintenance and
+// that the holder of permit, or a car without a permit if it is nil, may
+// take. The dedicated place of the permit comes first; otherwise the place is
+// chosen according to the allocation strategy: a random one, or the
+// lowest-numbered one to fill the lot from the entrance.
+func pickFreePlace(cfg *config.Config, occupiedSpaces []models.ParkingSpaceLog, blocked map[int]*models.MaintenanceBlock, reserved map[int]*models.Permit, permit *models.Permit) (int, bool) {
+	occupiedPlaceNumbers := make(map[int]bool)
+	for _, space := range occupiedSpaces {
+		occupiedPlaceNumbers[space.PlaceNumber] = true
+	}
+
+	if permit != nil && permit.PlaceNumber != nil {
+		place := *permit.PlaceNumber
+		if place <= cfg.ParkingSlotsCount && !occupiedPlaceNumbers[place] && blocked[place] == nil {
+			return place, true
+		}
+	}
+
+	var availablePlaces []int
+	for i := 1; i <= cfg.ParkingSlotsCount; i++ {
+		if !occupiedPlaceNumbers[i] && blocked[i] == nil && checkPlaceAccess(cfg, i, reserved, permit) == nil {
+			availablePlaces = append(availablePlaces, i)
+		}
+	}
+
+	if len(availablePlaces) == 0 {
+		return 0, false
+	}
+
+	if cfg.AllocationStrategy == config.AllocationLowest {
+		return availablePlaces[0], true
+	}
+	return availablePlaces[rand.Intn(len(availablePlaces))], true
+}
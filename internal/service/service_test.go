@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/amend-parking-backend/internal/config"
+	"github.com/amend-parking-backend/internal/models"
+)
+
+func TestPickFreePlace(t *testing.T) {
+	catalogue := config.SpaceCatalogue{
+		Zones: []config.PlaceLabel{
+			{FromPlace: 1, ToPlace: 4, Label: "A"},
+			{FromPlace: 5, ToPlace: 6, Label: "B"},
+		},
+	}
+	lowest := &config.Config{ParkingSlotsCount: 6, AllocationStrategy: config.AllocationLowest, Spaces: catalogue, PermitZones: []string{"B"}}
+	random := &config.Config{ParkingSlotsCount: 6, AllocationStrategy: config.AllocationRandom, Spaces: catalogue, PermitZones: []string{"B"}}
+	occupied := func(places ...int) []models.ParkingSpaceLog {
+		logs := make([]models.ParkingSpaceLog, len(places))
+		for i, place := range places {
+			logs[i] = models.ParkingSpaceLog{PlaceNumber: place}
+		}
+		return logs
+	}
+	dedicated, outside := 3, 9
+	holder := &models.Permit{PermitID: "holder", PlaceNumber: &dedicated}
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		occupied  []models.ParkingSpaceLog
+		blocked   map[int]*models.MaintenanceBlock
+		reserved  map[int]*models.Permit
+		permit    *models.Permit
+		wantPlace int
+		wantOK    bool
+	}{
+		{name: "lowest free place", cfg: lowest, occupied: occupied(1), wantPlace: 2, wantOK: true},
+		{name: "blocked place skipped", cfg: lowest, blocked: map[int]*models.MaintenanceBlock{1: {}}, wantPlace: 2, wantOK: true},
+		{name: "reserved place skipped", cfg: lowest, occupied: occupied(1, 2), reserved: map[int]*models.Permit{dedicated: holder}, wantPlace: 4, wantOK: true},
+		{name: "permit zone skipped without a permit", cfg: lowest, occupied: occupied(1, 2, 3, 4), wantOK: false},
+		{name: "permit zone with a permit", cfg: lowest, occupied: occupied(1, 2, 3, 4), permit: &models.Permit{PermitID: "other"}, wantPlace: 5, wantOK: true},
+		{name: "dedicated place first", cfg: lowest, reserved: map[int]*models.Permit{dedicated: holder}, permit: holder, wantPlace: dedicated, wantOK: true},
+		{name: "occupied dedicated place", cfg: lowest, occupied: occupied(dedicated), reserved: map[int]*models.Permit{dedicated: holder}, permit: holder, wantPlace: 1, wantOK: true},
+		{name: "blocked dedicated place", cfg: lowest, blocked: map[int]*models.MaintenanceBlock{dedicated: {}}, reserved: map[int]*models.Permit{dedicated: holder}, permit: holder, wantPlace: 1, wantOK: true},
+		{name: "dedicated place beyond the lot", cfg: lowest, occupied: occupied(1), permit: &models.Permit{PermitID: "other", PlaceNumber: &outside}, wantPlace: 2, wantOK: true},
+		{name: "full lot", cfg: lowest, occupied: occupied(1, 2, 3, 4), blocked: map[int]*models.MaintenanceBlock{5: {}, 6: {}}, permit: &models.Permit{PermitID: "other"}, wantOK: false},
+		{name: "random strategy with one free place", cfg: random, occupied: occupied(1, 3, 4), wantPlace: 2, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			place, ok := pickFreePlace(tt.cfg, tt.occupied, tt.blocked, tt.reserved, tt.permit)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && place != tt.wantPlace {
+				t.Errorf("place = %d, want %d", place, tt.wantPlace)
+			}
+		})
+	}
+}
+
+func TestApplyReloadKeepsRuntimeCapacity(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("PARKING_SERVICE_API_KEY", "key")
+	t.Setenv("PARKING_SLOTS_COUNT", "40")
+	t.Setenv("PARKING_HOURLY_RATE", "100")
+	loaded, err := config.Load("")
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	s := NewService(loaded, nil, nil, nil, slog.New(slog.DiscardHandler))
+	// A capacity change made at runtime, as SetCapacity stores it.
+	s.cfg.Store(loaded.WithSlotsCount(45))
+
+	t.Setenv("PARKING_HOURLY_RATE", "150")
+	next, err := config.Load("")
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	result, err := s.ApplyReload(context.Background(), next)
+	if err != nil {
+		t.Fatalf("ApplyReload() error = %v", err)
+	}
+
+	cfg := s.Config()
+	if cfg != result.Config {
+		t.Error("reloaded configuration not swapped in")
+	}
+	if cfg.ParkingSlotsCount != 45 {
+		t.Errorf("ParkingSlotsCount = %d, want the runtime value 45", cfg.ParkingSlotsCount)
+	}
+	if cfg.ParkingHourlyRate != 150 {
+		t.Errorf("ParkingHourlyRate = %d, want 150", cfg.ParkingHourlyRate)
+	}
+}